@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-plugin-demo/src/shared"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// maxBatchLine 输入中单行的最大长度
+const maxBatchLine = 1 << 20
+
+var (
+	batchInput       string
+	batchConcurrency int
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "批量调用插件方法",
+	Long: `从 JSONL 文件读取调用，每行一个 {"plugin", "method", "args", "options"}，在同一组插件进程上并发执行。
+args 和 options 的值可以是字符串、数字、布尔值，map 和切片类型的参数直接写成JSON对象和数组。
+结果按输入顺序逐行输出为 JSONL，每行带有输入的行号；单行调用失败时该行带有 error，不影响其他调用。
+有调用失败时命令退出码为 1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if batchConcurrency < 1 {
+			return shared.NewError(shared.CodeInvalidArgument, "并发数必须大于 0, 当前为 %d", batchConcurrency)
+		}
+		cmd.SilenceUsage = true
+
+		input := os.Stdin
+		if batchInput != "-" {
+			file, err := os.Open(batchInput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			input = file
+		}
+
+		pm, err := loadPlugins()
+		if err != nil {
+			return err
+		}
+		defer pm.UnloadAll()
+
+		total, failed, err := runBatch(pm, input, os.Stdout, batchConcurrency)
+		fmt.Fprintf(os.Stderr, "完成 %d 条调用, %d 条失败\n", total, failed)
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d/%d 条调用失败", failed, total)
+		}
+		return nil
+	},
+}
+
+// batchCall 输入中的一行
+type batchCall struct {
+	Plugin  string                     `json:"plugin"`
+	Method  string                     `json:"method"`
+	Args    []json.RawMessage          `json:"args"`
+	Options map[string]json.RawMessage `json:"options"`
+}
+
+// batchResult 输出中的一行, line 为输入的行号
+type batchResult struct {
+	Line int `json:"line"`
+	invokeResult
+}
+
+// runBatch 逐行读取调用并以最多 concurrency 个并发执行, 按输入顺序写出结果
+// 返回调用总数和失败数; 读取输入或写出结果出错时停止读取, 已开始的调用仍会执行完
+func runBatch(pm *shared.PluginManager, r io.Reader, w io.Writer, concurrency int) (int, int, error) {
+	// pending 按输入顺序排列进行中的调用, 容量限制预读的行数
+	pending := make(chan chan batchResult, concurrency)
+	sem := make(chan struct{}, concurrency)
+	stop := make(chan struct{})
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(pending)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLine)
+		line := 0
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			data = append([]byte(nil), data...)
+
+			select {
+			case <-stop:
+				readErr <- nil
+				return
+			default:
+			}
+			done := make(chan batchResult, 1)
+			pending <- done
+			sem <- struct{}{}
+			go func(line int) {
+				defer func() { <-sem }()
+				done <- runBatchLine(pm, line, data)
+			}(line)
+		}
+		readErr <- scanner.Err()
+	}()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	total, failed := 0, 0
+	var writeErr error
+	for done := range pending {
+		result := <-done
+		if writeErr != nil {
+			continue
+		}
+		total++
+		if result.Error != nil {
+			failed++
+		}
+		if writeErr = encoder.Encode(result); writeErr != nil {
+			close(stop)
+		}
+	}
+	if writeErr != nil {
+		return total, failed, writeErr
+	}
+	if err := <-readErr; err != nil {
+		return total, failed, fmt.Errorf("读取输入失败: %w", err)
+	}
+	return total, failed, nil
+}
+
+// runBatchLine 解析并执行一行调用, 错误记录在结果中
+func runBatchLine(pm *shared.PluginManager, line int, data []byte) batchResult {
+	result := batchResult{Line: line}
+	call, rawArgs, rawOptions, err := parseBatchCall(data)
+	result.Plugin, result.Method, result.Args = call.Plugin, call.Method, rawArgs
+	if err != nil {
+		result.Error = newErrorInfo(err)
+		return result
+	}
+
+	value, duration, err := callMethod(pm, call.Plugin, call.Method, rawArgs, rawOptions)
+	result.Duration = float64(duration.Microseconds()) / 1000
+	if err != nil {
+		result.Error = newErrorInfo(err)
+		return result
+	}
+	result.Value = value
+	result.Type = typeName(value)
+	return result
+}
+
+// parseBatchCall 解析一行调用, 参数和选项转换为文本后由 callMethod 按ABI转换类型
+func parseBatchCall(data []byte) (batchCall, []string, map[string]string, error) {
+	var call batchCall
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&call); err != nil {
+		return call, nil, nil, shared.NewError(shared.CodeInvalidArgument, "无法解析调用: %v", err)
+	}
+	if call.Plugin == "" || call.Method == "" {
+		return call, nil, nil, shared.NewError(shared.CodeInvalidArgument, "调用缺少 plugin 或 method")
+	}
+
+	args := make([]string, len(call.Args))
+	for i, raw := range call.Args {
+		arg, err := jsonText(raw)
+		if err != nil {
+			return call, nil, nil, shared.NewError(shared.CodeInvalidArgument, "第 %d 个参数: %v", i+1, err)
+		}
+		args[i] = arg
+	}
+	options := make(map[string]string, len(call.Options))
+	for name, raw := range call.Options {
+		option, err := jsonText(raw)
+		if err != nil {
+			return call, nil, nil, shared.NewError(shared.CodeInvalidArgument, "选项 %s: %v", name, err)
+		}
+		options[name] = option
+	}
+	return call, args, options, nil
+}
+
+// jsonText 返回JSON值的文本形式: 字符串取其内容, 数字、布尔值、对象和数组保持原样的JSON文本,
+// 对象和数组由 CoerceValue 按参数的 map、切片等类型解析
+func jsonText(raw json.RawMessage) (string, error) {
+	text := strings.TrimSpace(string(raw))
+	switch {
+	case strings.HasPrefix(text, `"`):
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case text == "null":
+		return "", fmt.Errorf("不支持的值 null, 省略可选参数即可使用默认值")
+	}
+	return text, nil
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&batchInput, "input", "i", "-", "调用列表文件 (JSONL), - 表示标准输入")
+	batchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "c", 4, "最大并发调用数")
+	rootCmd.AddCommand(batchCmd)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-plugin-demo/src/shared"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// startTestPlugins 编译 string_utils 和 calculator 到临时插件目录并加载
+func startTestPlugins(t *testing.T) *shared.PluginManager {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("跳过需要编译插件的测试")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"string_utils", "calculator"} {
+		cmd := exec.Command("go", "build", "-o", filepath.Join(dir, name), "go-plugin-demo/src/plugins/"+name)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("编译插件 %s 失败: %v\n%s", name, err, output)
+		}
+	}
+	pm := shared.NewPluginManager()
+	t.Cleanup(pm.UnloadAll)
+	if err := pm.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return pm
+}
+
+func TestRunBatch(t *testing.T) {
+	pm := startTestPlugins(t)
+
+	tests := []struct {
+		call  string
+		value interface{}
+		code  shared.ErrorCode // 为空表示调用成功
+	}{
+		{call: `{"plugin":"string_utils","method":"Join","args":[["a","b","c"],"-"]}`, value: "a-b-c"},
+		{call: `{"plugin":"calculator","method":"Add","args":[1.5,2]}`, value: 3.5},
+		{call: `{"plugin":"string_utils","method":"ToUpper","args":["abc"]}`, value: "ABC"},
+		{call: `{"plugin":"calculator","method":"Divide","args":[1,0]}`, code: shared.CodeInvalidArgument},
+		{call: `{"plugin":"string_utils","method":"Join","args":[{"a":1},"-"]}`, code: shared.CodeInvalidArgument},
+		{call: `{"plugin":"string_utils","method":"Join","args":[null,"-"]}`, code: shared.CodeInvalidArgument},
+		{call: `{"plugin":"string_utils","method":"Missing"}`, code: shared.CodeNotFound},
+		{call: `not json`, code: shared.CodeInvalidArgument},
+		{call: `{"plugin":"string_utils","method":"Reverse","args":["héllo"]}`, value: "olléh"},
+	}
+
+	// 空行不计入调用, 但计入行号
+	var input strings.Builder
+	lines := make([]int, len(tests))
+	line := 0
+	for i, tt := range tests {
+		if i == 2 {
+			input.WriteString("\n")
+			line++
+		}
+		input.WriteString(tt.call + "\n")
+		line++
+		lines[i] = line
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		var output bytes.Buffer
+		total, failed, err := runBatch(pm, strings.NewReader(input.String()), &output, concurrency)
+		if err != nil {
+			t.Fatalf("并发 %d: runBatch 返回 %v", concurrency, err)
+		}
+		if total != len(tests) || failed != 5 {
+			t.Errorf("并发 %d: 调用 %d 条, 失败 %d 条, 期望 %d 和 5", concurrency, total, failed, len(tests))
+		}
+
+		results := strings.Split(strings.TrimSpace(output.String()), "\n")
+		if len(results) != len(tests) {
+			t.Fatalf("并发 %d: 输出 %d 行, 期望 %d 行", concurrency, len(results), len(tests))
+		}
+		for i, tt := range tests {
+			var result struct {
+				Line  int         `json:"line"`
+				Value interface{} `json:"value"`
+				Error *errorInfo  `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(results[i]), &result); err != nil {
+				t.Fatalf("无法解析输出 %s: %v", results[i], err)
+			}
+			if result.Line != lines[i] {
+				t.Errorf("并发 %d: 第 %d 条结果的行号为 %d, 期望 %d", concurrency, i+1, result.Line, lines[i])
+			}
+			switch {
+			case tt.code != "" && (result.Error == nil || result.Error.Code != tt.code):
+				t.Errorf("并发 %d: %s 的结果为 %s, 期望错误 %s", concurrency, tt.call, results[i], tt.code)
+			case tt.code == "" && (result.Error != nil || result.Value != tt.value):
+				t.Errorf("并发 %d: %s 的结果为 %s, 期望 %v", concurrency, tt.call, results[i], tt.value)
+			}
+		}
+	}
+}
+
+func TestParseBatchCall(t *testing.T) {
+	tests := []struct {
+		line        string
+		wantArgs    []string
+		wantOptions map[string]string
+		wantErr     string // 为空表示解析成功
+	}{
+		{line: `{"plugin":"date_utils","method":"AddDays","args":["2024-01-02",3],"options":{"layout":"2006/01/02"}}`,
+			wantArgs: []string{"2024-01-02", "3"}, wantOptions: map[string]string{"layout": "2006/01/02"}},
+		{line: `{"plugin":"date_utils","method":"Now"}`, wantArgs: []string{}, wantOptions: map[string]string{}},
+		{line: `{"plugin":"date_utils"}`, wantErr: "缺少 plugin 或 method"},
+		{line: `{"plugin":"date_utils","method":"Now","timeout":"1s"}`, wantErr: "无法解析调用"},
+		{line: `{"plugin":"date_utils","method":"AddDays","args":[null]}`, wantErr: "第 1 个参数"},
+		{line: `{"plugin":"date_utils","method":"Now","options":{"layout":null}}`, wantErr: "选项 layout"},
+	}
+	for _, tt := range tests {
+		_, args, options, err := parseBatchCall([]byte(tt.line))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || shared.CodeOf(err) != shared.CodeInvalidArgument {
+				t.Errorf("parseBatchCall(%s) 返回 %v, 期望包含 %q 的 %s", tt.line, err, tt.wantErr, shared.CodeInvalidArgument)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(args, tt.wantArgs) || !reflect.DeepEqual(options, tt.wantOptions) {
+			t.Errorf("parseBatchCall(%s) = %q, %q, %v", tt.line, args, options, err)
+		}
+	}
+}
+
+func TestRunBatchOrder(t *testing.T) {
+	pm := shared.NewPluginManager()
+	input := "{\"plugin\":\"a\",\"method\":\"M\"}\n\nnot json\n{\"plugin\":\"b\",\"method\":\"M\"}\n"
+
+	for _, concurrency := range []int{1, 4} {
+		var output bytes.Buffer
+		total, failed, err := runBatch(pm, strings.NewReader(input), &output, concurrency)
+		if err != nil || total != 3 || failed != 3 {
+			t.Fatalf("并发 %d: runBatch = %d, %d, %v, 期望 3 条调用全部失败", concurrency, total, failed, err)
+		}
+
+		var lines []int
+		for _, row := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var result struct {
+				Line  int        `json:"line"`
+				Error *errorInfo `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(row), &result); err != nil || result.Error == nil {
+				t.Fatalf("并发 %d: 无法解析输出 %s: %v", concurrency, row, err)
+			}
+			lines = append(lines, result.Line)
+		}
+		if !reflect.DeepEqual(lines, []int{1, 3, 4}) {
+			t.Errorf("并发 %d: 结果的行号为 %v, 期望按输入顺序 [1 3 4]", concurrency, lines)
+		}
+	}
+}
+
+func TestJSONText(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: `"a b"`, want: "a b"},
+		{raw: `"中"`, want: "中"},
+		{raw: `42`, want: "42"},
+		{raw: `-1.5e3`, want: "-1.5e3"},
+		{raw: `true`, want: "true"},
+		{raw: `["a", 1]`, want: `["a", 1]`},
+		{raw: `{"k": "v"}`, want: `{"k": "v"}`},
+		{raw: `null`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := jsonText(json.RawMessage(tt.raw))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("jsonText(%s) = %q, %v, 期望 %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
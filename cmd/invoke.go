@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-plugin-demo/src/shared"
-	"strconv"
+	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -11,90 +14,164 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var invokeOptions []string
+
 var invokeCmd = &cobra.Command{
 	Use:   "invoke [插件名] [方法名] [参数...]",
 	Short: "调用插件方法",
-	Long:  "调用指定插件的指定方法，并传入相应参数",
+	Long:  "调用指定插件的指定方法，并传入相应参数；关键字选项用 --opt key=value 传入，可以重复",
 	Args:  cobra.MinimumNArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		pluginName := args[0]
-		methodName := args[1]
-		methodArgs := args[2:]
-
-		// 初始化彩色输出
-		red := color.New(color.FgRed).SprintFunc()
-		green := color.New(color.FgGreen).SprintFunc()
-		blue := color.New(color.FgBlue).SprintFunc()
-
-		// 加载插件
-		pm := shared.NewPluginManager()
-		defer pm.UnloadAll()
-
-		client, ok := pm.Plugins[pluginName]
-		if !ok {
-			fmt.Println(red("错误: 插件未加载 - " + pluginName))
-			return
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(); err != nil {
+			return err
 		}
+		// 参数个数正确后出错不再打印用法
+		cmd.SilenceUsage = true
 
-		// 获取插件实例
-		rpcClient, err := client.Client()
+		result := invokeResult{Plugin: args[0], Method: args[1], Args: args[2:]}
+		value, duration, err := invoke(result.Plugin, result.Method, result.Args)
+		result.Duration = float64(duration.Microseconds()) / 1000
 		if err != nil {
-			fmt.Println(red("RPC连接失败:"), err)
-			return
+			if outputFormat == outputTable {
+				return err
+			}
+			result.Error = newErrorInfo(err)
+			if writeErr := writeStructured(os.Stdout, outputFormat, result); writeErr != nil {
+				return writeErr
+			}
+			return silentError{err}
 		}
 
-		raw, err := rpcClient.Dispense(pluginName)
-		if err != nil {
-			fmt.Println(red("获取插件实例失败:"), err)
-			return
+		result.Value = value
+		result.Type = typeName(value)
+		if outputFormat != outputTable {
+			return writeStructured(os.Stdout, outputFormat, result)
 		}
+		printResult(os.Stdout, result)
+		return nil
+	},
+}
 
-		// 转换参数类型
-		convertedArgs := make([]interface{}, len(methodArgs))
-		for i, arg := range methodArgs {
-			switch pluginName {
-			case "calculator":
-				if num, err := strconv.Atoi(arg); err == nil {
-					convertedArgs[i] = num
-				} else {
-					convertedArgs[i] = arg
-				}
-			case "date_utils":
-				if methodName == "AddDays" && i == 0 {
-					if t, err := time.Parse("2006-01-02", arg); err == nil {
-						convertedArgs[i] = t
-					} else {
-						convertedArgs[i] = arg
-					}
-				}
-			default:
-				convertedArgs[i] = arg
-			}
-		}
+// invokeResult invoke 命令的输出
+type invokeResult struct {
+	Plugin   string      `json:"plugin"`
+	Method   string      `json:"method"`
+	Args     []string    `json:"args"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type,omitempty"`
+	Duration float64     `json:"duration_ms"`
+	Error    *errorInfo  `json:"error,omitempty"`
+}
 
-		// 调用方法
-		var result interface{}
-		switch p := raw.(type) {
-		case shared.DynamicPlugin:
-			result, err = p.Invoke(methodName, convertedArgs...)
-		default:
-			err = fmt.Errorf("不支持的插件类型")
-		}
+// errorInfo 结构化输出中的错误, 消息是完整的错误文本
+type errorInfo struct {
+	Code    shared.ErrorCode  `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
 
-		if err != nil {
-			fmt.Println(red("调用失败:"), err)
-			return
+func newErrorInfo(err error) *errorInfo {
+	info := &errorInfo{Code: shared.CodeOf(err), Message: err.Error()}
+	var pluginErr *shared.Error
+	if errors.As(err, &pluginErr) {
+		info.Details = pluginErr.Details
+	}
+	return info
+}
+
+// invoke 加载插件并调用方法
+func invoke(pluginName, methodName string, methodArgs []string) (interface{}, time.Duration, error) {
+	pm, err := loadPlugins()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer pm.UnloadAll()
+
+	rawOptions, err := parseOptions(invokeOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("选项错误: %w", err)
+	}
+	return callMethod(pm, pluginName, methodName, methodArgs, rawOptions)
+}
+
+// callMethod 按ABI声明的类型转换参数和选项后调用方法, 返回的耗时只包含方法调用本身
+func callMethod(pm *shared.PluginManager, pluginName, methodName string, rawArgs []string, rawOptions map[string]string) (interface{}, time.Duration, error) {
+	abi, ok := pm.ABI(pluginName)
+	if !ok {
+		return nil, 0, shared.NewError(shared.CodeNotFound, "插件未加载 - %s", pluginName)
+	}
+
+	spec, ok := abi.Methods[methodName]
+	if !ok {
+		return nil, 0, shared.NewError(shared.CodeNotFound, "方法不存在 - %s.%s", pluginName, methodName)
+	}
+
+	args, err := shared.CoerceArgs(spec, rawArgs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("参数错误: %w", err)
+	}
+	options, err := shared.CoerceOptions(spec, rawOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("选项错误: %w", err)
+	}
+
+	start := time.Now()
+	result, err := pm.InvokeOptions(context.Background(), pluginName, methodName, args, options)
+	duration := time.Since(start)
+	if err != nil {
+		return nil, duration, fmt.Errorf("调用失败: %w", err)
+	}
+	return result, duration, nil
+}
+
+// typeName 返回值的Go类型, nil 为 "null"
+func typeName(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// printResult 以表格形式输出调用结果
+func printResult(w io.Writer, result invokeResult) {
+	blue := color.New(color.FgBlue).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	t := &table{}
+	t.add("调用:", result.Plugin+"."+result.Method+"("+strings.Join(result.Args, ", ")+")")
+	t.add("返回值:", fmt.Sprint(result.Value))
+	t.add("类型:", result.Type)
+	t.add("耗时:", fmt.Sprintf("%.3fms", result.Duration))
+	t.colorize = func(row, col int, text string) string {
+		switch {
+		case col == 0:
+			return blue(text)
+		case row == 0:
+			return green(text)
 		}
+		return text
+	}
+	t.write(w)
+}
 
-		fmt.Printf("%s %s.%s(%s)\n",
-			blue("调用结果:"),
-			green(pluginName),
-			green(methodName),
-			strings.Join(methodArgs, ", "))
-		fmt.Printf("%s %v\n", blue("返回值:"), result)
-	},
+// parseOptions 解析 key=value 形式的选项, 同一选项不能重复
+func parseOptions(raw []string) (map[string]string, error) {
+	options := make(map[string]string, len(raw))
+	for _, item := range raw {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || key == "" {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "选项 %q 的格式应为 key=value", item)
+		}
+		if _, dup := options[key]; dup {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "选项 %s 重复", key)
+		}
+		options[key] = value
+	}
+	return options, nil
 }
 
 func init() {
+	addOutputFlag(invokeCmd)
+	invokeCmd.Flags().StringArrayVar(&invokeOptions, "opt", nil, "关键字选项, 格式为 key=value")
 	rootCmd.AddCommand(invokeCmd)
 }
@@ -1,102 +1,247 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"go-plugin-demo/src/shared"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
-	"github.com/hashicorp/go-plugin"
 	"github.com/spf13/cobra"
 )
 
-type PluginConfig struct {
-	Name      string                 `json:"name"`
-	Path      string                 `json:"path"`
-	Handshake plugin.HandshakeConfig `json:"handshake"`
+// 插件信息的来源
+const (
+	sourceLive     = "live"     // 运行中的插件报告的ABI
+	sourceManifest = "manifest" // 插件未能启动, 读取ABI描述文件
+)
+
+// stateUnavailable 插件未能启动
+const stateUnavailable = "unavailable"
+
+// pluginInfo list 命令输出的单个插件
+type pluginInfo struct {
+	Name    string       `json:"name"`
+	Path    string       `json:"path"`
+	Version string       `json:"version,omitempty"`
+	State   string       `json:"state"`
+	Healthy bool         `json:"healthy"`
+	Error   string       `json:"error,omitempty"`
+	Source  string       `json:"source,omitempty"` // 方法列表的来源, 为空表示没有可用的ABI
+	Methods []methodInfo `json:"methods"`
 }
 
-type Config struct {
-	Plugins []PluginConfig `json:"plugins"`
+// methodInfo 插件的一个方法
+type methodInfo struct {
+	Name    string             `json:"name"`
+	Params  []shared.ParamSpec `json:"params"`
+	Options []shared.ParamSpec `json:"options,omitempty"`
+	Returns string             `json:"returns"`
+	Help    string             `json:"help,omitempty"`
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "列出所有插件及方法",
-	Long:  "列出当前系统中所有已注册的插件及其可用方法",
-	Run: func(cmd *cobra.Command, args []string) {
-		// 读取插件配置
-		configFile, err := os.ReadFile("config/plugins.json")
-		if err != nil {
-			color.Red("读取插件配置文件失败: %v", err)
-			return
+	Long:  "启动配置文件中的插件，列出插件报告的方法、帮助信息和版本；未能启动的插件标记为不可用，并尽量从ABI描述文件读取方法",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(); err != nil {
+			return err
 		}
+		cmd.SilenceUsage = true
 
-		var config Config
-		if err := json.Unmarshal(configFile, &config); err != nil {
-			color.Red("解析插件配置失败: %v", err)
-			return
+		pm, config, loadErrs, err := startPlugins()
+		if err != nil {
+			return err
 		}
+		defer pm.UnloadAll()
 
-		// 初始化彩色输出
-		blue := color.New(color.FgBlue).SprintFunc()
-		green := color.New(color.FgGreen).SprintFunc()
-		yellow := color.New(color.FgYellow).SprintFunc()
-
-		fmt.Println(blue("\n已注册插件:"))
-		for _, plugin := range config.Plugins {
-			fmt.Printf("\n%s %s\n", green("插件名称:"), plugin.Name)
-			fmt.Printf("%s %s\n", green("插件路径:"), plugin.Path)
-
-			// 获取插件ABI信息
-			abi := getPluginABI(plugin.Name)
-			if abi != nil {
-				fmt.Println(yellow("\n可用方法:"))
-				for method, spec := range abi.Methods {
-					fmt.Printf("  %s(", method)
-					for i, param := range spec.Params {
-						if i > 0 {
-							fmt.Print(", ")
-						}
-						fmt.Print(param)
-					}
-					fmt.Printf(") → %s\n", spec.Returns)
-				}
-			}
+		plugins := collectPlugins(pm, config, loadErrs)
+		if outputFormat != outputTable {
+			return writeStructured(os.Stdout, outputFormat, map[string]interface{}{"plugins": plugins})
 		}
+		printPlugins(os.Stdout, plugins)
+		return nil
 	},
 }
 
-func getPluginABI(name string) *shared.PluginABI {
-	// 这里简化处理，实际应从插件获取ABI信息
-	switch name {
-	case "calculator":
-		return shared.CalculatorABI()
-	case "string_utils":
-		return &shared.PluginABI{
-			Name:    "string_utils",
-			Version: "1.0.0",
-			Methods: map[string]shared.MethodSpec{
-				"Reverse": {Params: []string{"string"}, Returns: "string"},
-				"ToUpper": {Params: []string{"string"}, Returns: "string"},
-				"ToCamel": {Params: []string{"string"}, Returns: "string"},
-			},
+// collectPlugins 汇总配置中的插件、插件目录中加载的插件和加载失败的插件, 按配置顺序排列
+func collectPlugins(pm *shared.PluginManager, config *shared.Config, loadErrs []error) []pluginInfo {
+	// 插件目录中加载失败的插件同样带有插件名, 按名称和路径与配置中的插件对应
+	type pluginKey struct{ name, path string }
+	failed := make(map[pluginKey]*shared.LoadError)
+	var failedOrder []pluginKey
+	for _, err := range loadErrs {
+		var loadErr *shared.LoadError
+		if !errors.As(err, &loadErr) {
+			fmt.Fprintln(os.Stderr, color.New(color.FgYellow).Sprint("警告:"), err)
+			continue
+		}
+		key := pluginKey{loadErr.Name, loadErr.Path}
+		failed[key] = loadErr
+		failedOrder = append(failedOrder, key)
+	}
+
+	var plugins []pluginInfo
+	seen := make(map[string]bool)
+	for _, pluginConfig := range config.Plugins {
+		seen[pluginConfig.Name] = true
+		if info, ok := livePlugin(pm, pluginConfig.Name); ok {
+			plugins = append(plugins, info)
+			continue
+		}
+		var err error = fmt.Errorf("插件 %s 未加载", pluginConfig.Name)
+		key := pluginKey{pluginConfig.Name, pluginConfig.Path}
+		if loadErr, ok := failed[key]; ok {
+			err = loadErr
+			delete(failed, key)
+		}
+		plugins = append(plugins, unavailablePlugin(pluginConfig.Name, pluginConfig.Path, err))
+	}
+
+	// 插件目录中的插件以文件名注册
+	for _, name := range pm.Names() {
+		if seen[name] {
+			continue
+		}
+		if info, ok := livePlugin(pm, name); ok {
+			plugins = append(plugins, info)
+		}
+	}
+	for _, key := range failedOrder {
+		if loadErr, ok := failed[key]; ok {
+			plugins = append(plugins, unavailablePlugin(loadErr.Name, loadErr.Path, loadErr))
+			delete(failed, key)
+		}
+	}
+	return plugins
+}
+
+// livePlugin 返回运行中插件报告的信息
+func livePlugin(pm *shared.PluginManager, name string) (pluginInfo, bool) {
+	status, ok := pm.Status(name)
+	if !ok {
+		return pluginInfo{}, false
+	}
+	abi, ok := pm.ABI(name)
+	if !ok {
+		return pluginInfo{}, false
+	}
+	return pluginInfo{
+		Name:    name,
+		Path:    status.Path,
+		Version: abi.Version,
+		State:   string(status.State),
+		Healthy: status.Healthy(),
+		Error:   status.LastError,
+		Source:  sourceLive,
+		Methods: methodInfos(abi),
+	}, true
+}
+
+// unavailablePlugin 返回未能启动的插件, 插件带有ABI描述文件时从中读取方法
+func unavailablePlugin(name, path string, err error) pluginInfo {
+	info := pluginInfo{
+		Name:    name,
+		Path:    path,
+		State:   stateUnavailable,
+		Error:   err.Error(),
+		Methods: []methodInfo{},
+	}
+	manifestPath, ok := shared.FindABIManifest(path)
+	if !ok {
+		return info
+	}
+	abi, err := shared.ReadABIManifest(manifestPath)
+	if err != nil {
+		info.Error += "; " + err.Error()
+		return info
+	}
+	info.Version = abi.Version
+	info.Source = sourceManifest
+	info.Methods = methodInfos(abi)
+	return info
+}
+
+// methodInfos 按方法名排序返回ABI中的方法
+func methodInfos(abi *shared.PluginABI) []methodInfo {
+	methods := make([]methodInfo, 0, len(abi.Methods))
+	for name, spec := range abi.Methods {
+		params := spec.Params
+		if params == nil {
+			params = []shared.ParamSpec{}
+		}
+		methods = append(methods, methodInfo{
+			Name:    name,
+			Params:  params,
+			Options: spec.Options,
+			Returns: spec.Returns,
+			Help:    spec.Help,
+		})
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
+
+// printPlugins 以表格形式输出插件, 每个方法一行, 加载错误列在表格之后
+func printPlugins(w io.Writer, plugins []pluginInfo) {
+	blue := color.New(color.FgBlue).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	t := &table{header: []string{"插件", "版本", "状态", "方法", "参数", "选项", "返回值", "说明"}}
+	healthy := make(map[int]bool)
+	for _, plugin := range plugins {
+		first := len(t.rows)
+		healthy[first] = plugin.Healthy
+		if len(plugin.Methods) == 0 {
+			t.add(plugin.Name, dash(plugin.Version), plugin.State, "-", "", "", "", "")
+			continue
+		}
+		for i, method := range plugin.Methods {
+			help := method.Help
+			if plugin.Source == sourceManifest {
+				help = strings.TrimSpace("(ABI描述文件) " + help)
+			}
+			row := []string{"", "", "", method.Name, shared.JoinParams(method.Params), shared.JoinParams(method.Options), method.Returns, help}
+			if i == 0 {
+				row[0], row[1], row[2] = plugin.Name, dash(plugin.Version), plugin.State
+			}
+			t.add(row...)
 		}
-	case "date_utils":
-		return &shared.PluginABI{
-			Name:    "date_utils",
-			Version: "1.0.0",
-			Methods: map[string]shared.MethodSpec{
-				"AddDays": {Params: []string{"time.Time", "int"}, Returns: "time.Time"},
-				"Format":  {Params: []string{"time.Time", "string"}, Returns: "string"},
-				"Between": {Params: []string{"time.Time", "time.Time"}, Returns: "int"},
-			},
+	}
+	t.colorize = func(row, col int, text string) string {
+		switch {
+		case row < 0:
+			return blue(text)
+		case col == 2 && text != "" && healthy[row]:
+			return green(text)
+		case col == 2 && text != "":
+			return red(text)
 		}
+		return text
+	}
+	t.write(w)
+
+	for _, plugin := range plugins {
+		if plugin.Error != "" {
+			fmt.Fprintf(w, "\n%s %s: %s\n", red("错误:"), plugin.Name, plugin.Error)
+		}
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
 	}
-	return nil
+	return s
 }
 
 func init() {
+	addOutputFlag(listCmd)
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-plugin-demo/src/shared"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/width"
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// outputFormat list 和 invoke 的 --output 参数
+var outputFormat string
+
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "输出格式: table、json 或 yaml")
+}
+
+// checkOutputFormat 检查 --output 参数
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return shared.NewError(shared.CodeInvalidArgument, "不支持的输出格式 %q, 可选 table、json、yaml", outputFormat)
+}
+
+// writeStructured 以JSON或YAML格式输出 v
+// YAML由JSON转换而来, 字段名和顺序与JSON一致
+func writeStructured(w io.Writer, format string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == outputJSON {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle 去掉JSON解析得到的流式风格和引号, 由编码器按需要加引号
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// silentError 已经按输出格式报告过的错误, Execute 只设置退出码不再打印
+type silentError struct {
+	err error
+}
+
+func (e silentError) Error() string {
+	return e.err.Error()
+}
+
+func (e silentError) Unwrap() error {
+	return e.err
+}
+
+// table 按显示宽度对齐的表格, 中文等宽字符按两列计算
+type table struct {
+	header []string
+	rows   [][]string
+	// colorize 为单元格着色, row 为 -1 表示表头; 着色在对齐之后进行, 不影响宽度计算
+	colorize func(row, col int, text string) string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) write(w io.Writer) {
+	var widths []int
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if displayWidth(cell) > widths[i] {
+				widths[i] = displayWidth(cell)
+			}
+		}
+	}
+
+	writeRow := func(row int, cells []string) {
+		var b strings.Builder
+		for i, cell := range cells {
+			text := cell
+			if t.colorize != nil {
+				text = t.colorize(row, i, cell)
+			}
+			b.WriteString(text)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+	if len(t.header) > 0 {
+		writeRow(-1, t.header)
+	}
+	for i, row := range t.rows {
+		writeRow(i, row)
+	}
+}
+
+// displayWidth 字符串在终端中占用的列数
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"go-plugin-demo/src/shared"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// defaultConfigPath --config 的默认值
+const defaultConfigPath = "config/plugins.json"
+
+// configPath 全局 --config 参数指定的配置文件
+var configPath string
+
+// loadPlugins 按配置文件启动插件进程
+// 配置文件无法读取时返回错误; 单个插件加载失败只输出警告, 其余插件照常使用
+// 调用方使用完毕后需调用 UnloadAll 结束插件进程
+func loadPlugins() (*shared.PluginManager, error) {
+	pm, _, loadErrs, err := startPlugins()
+	if err != nil {
+		return nil, err
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for _, e := range loadErrs {
+		fmt.Fprintln(os.Stderr, yellow("警告:"), e)
+	}
+	return pm, nil
+}
+
+// startPlugins 按配置文件启动插件进程, 返回读取的配置和各个插件的加载错误
+func startPlugins() (*shared.PluginManager, *shared.Config, []error, error) {
+	config, err := shared.ReadConfig(configPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pm := shared.NewPluginManager()
+	var loadErrs []error
+	if err := pm.LoadConfig(config); err != nil {
+		loadErrs = shared.SplitErrors(err)
+	}
+	return pm, config, loadErrs, nil
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"go-plugin-demo/src/shared"
 	"os"
 
 	"github.com/fatih/color"
@@ -16,22 +18,60 @@ var rootCmd = &cobra.Command{
 支持以下子命令:
   list    - 列出所有插件及方法
   invoke  - 调用插件方法
-  help    - 显示详细帮助信息`,
+  shell   - 交互式调用插件
+  batch   - 批量调用插件方法
+  keygen  - 生成签名密钥
+  sign    - 签名插件
+  help    - 显示详细帮助信息
+
+退出码:
+  0  成功
+  1  其他错误
+  2  参数或选项无效
+  3  插件或方法不存在
+  4  插件不可用
+  5  调用超时`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
+	SilenceErrors: true,
+}
+
+// exitCodes 插件错误码对应的退出码, 其他错误退出码为 1
+var exitCodes = map[shared.ErrorCode]int{
+	shared.CodeInvalidArgument: 2,
+	shared.CodeNotFound:        3,
+	shared.CodeUnavailable:     4,
+	shared.CodeTimeout:         5,
+}
+
+// exitCode 按错误码返回命令的退出码
+func exitCode(err error) int {
+	if code, ok := exitCodes[shared.CodeOf(err)]; ok {
+		return code
+	}
+	return 1
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		// 以 json/yaml 输出的错误已经写入标准输出
+		var silent silentError
+		if !errors.As(err, &silent) {
+			red := color.New(color.FgRed).SprintFunc()
+			fmt.Fprintln(os.Stderr, red("错误:"), err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
-	// 初始化彩色输出
-	color.NoColor = false
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", defaultConfigPath, "插件配置文件")
+
+	// 输出不是终端时自动关闭颜色, 设置了 NO_COLOR 环境变量时也关闭 (https://no-color.org)
+	if os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+	}
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
 		// 自定义帮助输出颜色
 		blue := color.New(color.FgBlue).SprintFunc()
@@ -42,6 +82,12 @@ func init() {
 		for _, cmd := range cmd.Commands() {
 			fmt.Printf("  %-15s %s\n", cmd.Name(), cmd.Short)
 		}
+		if flags := cmd.LocalFlags().FlagUsages(); flags != "" {
+			fmt.Printf("\n%s\n%s", green("参数:"), flags)
+		}
+		if flags := cmd.InheritedFlags().FlagUsages(); flags != "" {
+			fmt.Printf("\n%s\n%s", green("全局参数:"), flags)
+		}
 		fmt.Printf("\n使用 \"%s [command] --help\" 查看命令详情\n", cmd.CommandPath())
 	})
 }
@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-plugin-demo/src/shared"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// historyFile shell 的 --history 参数
+var historyFile string
+
+// shellCommands shell 内置命令, 用于补全
+var shellCommands = []string{"help", "list", "exit", "quit"}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "交互式调用插件",
+	Long: `启动配置文件中的插件并进入交互式命令行，插件在各条命令之间保持运行。
+Tab 补全插件名、方法名和选项名；调用时省略参数会按参数类型逐个提示输入；命令历史保存在 --history 指定的文件中`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		pm, config, loadErrs, err := startPlugins()
+		if err != nil {
+			return err
+		}
+		defer pm.UnloadAll()
+
+		rl, err := readline.NewEx(&readline.Config{
+			Prompt:                 "plugin> ",
+			HistoryFile:            historyFile,
+			DisableAutoSaveHistory: true,
+			AutoComplete:           &shellCompleter{pm: pm},
+			InterruptPrompt:        "^C",
+			EOFPrompt:              "exit",
+		})
+		if err != nil {
+			return err
+		}
+		defer rl.Close()
+
+		s := &shell{rl: rl, out: os.Stdout, errOut: os.Stderr, pm: pm, config: config, loadErrs: loadErrs}
+		s.run()
+		return nil
+	},
+}
+
+// lineReader 逐行读取输入, 由 *readline.Instance 实现, 测试中可替换
+type lineReader interface {
+	Readline() (string, error)
+	SetPrompt(prompt string)
+	SaveHistory(line string) error
+}
+
+// shell 一次交互式会话, 插件在会话期间保持运行
+type shell struct {
+	rl       lineReader
+	out      io.Writer // 调用结果和帮助信息
+	errOut   io.Writer // 错误和警告
+	pm       *shared.PluginManager
+	config   *shared.Config
+	loadErrs []error
+}
+
+func (s *shell) run() {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for _, e := range s.loadErrs {
+		fmt.Fprintln(s.errOut, yellow("警告:"), e)
+	}
+	fmt.Fprintf(s.out, "已加载插件: %s\n输入 help 查看命令, exit 退出\n", strings.Join(s.pm.Names(), ", "))
+
+	for {
+		line, err := s.rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s.rl.SaveHistory(line)
+
+		words, err := splitWords(line)
+		if err != nil {
+			s.printError(err)
+			continue
+		}
+		switch words[0] {
+		case "exit", "quit":
+			return
+		case "help":
+			s.help(words[1:])
+		case "list":
+			printPlugins(s.out, collectPlugins(s.pm, s.config, s.loadErrs))
+		default:
+			if err := s.call(words[0], words[1:]); err != nil {
+				s.printError(err)
+			}
+		}
+	}
+}
+
+// help 处理 help、help <插件> 和 help <插件>.<方法>
+func (s *shell) help(args []string) {
+	if len(args) == 0 {
+		t := &table{}
+		t.add("<插件>.<方法> [参数...] [--选项=值...]", "调用方法, 省略参数时逐个提示输入")
+		t.add("help <插件>", "列出插件的方法")
+		t.add("help <插件>.<方法>", "显示方法的参数、选项和帮助信息")
+		t.add("list", "列出所有插件及状态")
+		t.add("exit", "退出")
+		t.write(s.out)
+		return
+	}
+
+	pluginName, method, _ := strings.Cut(args[0], ".")
+	abi, ok := s.pm.ABI(pluginName)
+	if !ok {
+		s.printError(shared.NewError(shared.CodeNotFound, "插件未加载 - %s", pluginName))
+		return
+	}
+	if method == "" {
+		fmt.Fprintf(s.out, "%s %s\n", pluginName, abi.Version)
+		t := &table{header: []string{"方法", "返回值", "说明"}}
+		for _, info := range methodInfos(abi) {
+			t.add(signature(info.Name, info.Params), info.Returns, info.Help)
+		}
+		t.write(s.out)
+		return
+	}
+
+	spec, ok := abi.Methods[method]
+	if !ok {
+		s.printError(shared.NewError(shared.CodeNotFound, "方法不存在 - %s.%s", pluginName, method))
+		return
+	}
+	help, err := s.pm.Help(context.Background(), pluginName, method)
+	if err != nil {
+		s.printError(err)
+		return
+	}
+	fmt.Fprintf(s.out, "%s.%s → %s\n", pluginName, signature(method, spec.Params), spec.Returns)
+	if help != "" {
+		fmt.Fprintf(s.out, "  %s\n", help)
+	}
+	printParams(s.out, "参数:", spec.Params)
+	printParams(s.out, "选项:", spec.Options)
+}
+
+// call 调用 <插件>.<方法>, 没有给出参数时按参数描述逐个提示输入
+func (s *shell) call(target string, words []string) error {
+	pluginName, method, ok := strings.Cut(target, ".")
+	if !ok {
+		return shared.NewError(shared.CodeInvalidArgument, "未知命令 %s, 输入 help 查看命令", target)
+	}
+	abi, ok := s.pm.ABI(pluginName)
+	if !ok {
+		return shared.NewError(shared.CodeNotFound, "插件未加载 - %s", pluginName)
+	}
+	spec, ok := abi.Methods[method]
+	if !ok {
+		return shared.NewError(shared.CodeNotFound, "方法不存在 - %s.%s", pluginName, method)
+	}
+
+	var rawArgs, rawOptions []string
+	for _, word := range words {
+		if option, ok := strings.CutPrefix(word, "--"); ok {
+			rawOptions = append(rawOptions, option)
+		} else {
+			rawArgs = append(rawArgs, word)
+		}
+	}
+	options, err := parseOptions(rawOptions)
+	if err != nil {
+		return fmt.Errorf("选项错误: %w", err)
+	}
+	if len(words) == 0 && (len(spec.Params) > 0 || len(spec.Options) > 0) {
+		if rawArgs, options, ok = s.promptArgs(spec); !ok {
+			return nil
+		}
+	}
+
+	value, duration, err := callMethod(s.pm, pluginName, method, rawArgs, options)
+	if err != nil {
+		return err
+	}
+	printResult(s.out, invokeResult{
+		Plugin:   pluginName,
+		Method:   method,
+		Args:     rawArgs,
+		Value:    value,
+		Type:     typeName(value),
+		Duration: float64(duration.Microseconds()) / 1000,
+	})
+	return nil
+}
+
+// promptArgs 按参数类型逐个读取参数和选项, 输入的值立即按类型检查
+// 留空的可选参数及其后的参数都不传, 由 CoerceArgs 补齐默认值; 按 Ctrl-C 或 Ctrl-D 取消调用
+func (s *shell) promptArgs(spec shared.MethodSpec) ([]string, map[string]string, bool) {
+	defer s.rl.SetPrompt("plugin> ")
+
+	var args []string
+	for _, param := range spec.Params {
+		hint := "必填"
+		if param.Optional {
+			hint = "可留空"
+		}
+		value, ok := s.promptValue(fmt.Sprintf("  %s (%s): ", param, hint), param, param.Optional)
+		if !ok {
+			return nil, nil, false
+		}
+		if value == "" {
+			break
+		}
+		args = append(args, value)
+	}
+
+	options := make(map[string]string)
+	for _, option := range spec.Options {
+		value, ok := s.promptValue(fmt.Sprintf("  选项 %s (可留空): ", option), option, true)
+		if !ok {
+			return nil, nil, false
+		}
+		if value != "" {
+			options[option.Name] = value
+		}
+	}
+	return args, options, true
+}
+
+// promptValue 读取一个值, 值不符合参数类型时重新读取
+func (s *shell) promptValue(label string, param shared.ParamSpec, optional bool) (string, bool) {
+	s.rl.SetPrompt(label)
+	for {
+		value, err := s.rl.Readline()
+		if err != nil {
+			return "", false
+		}
+		value = strings.TrimSpace(value)
+		if value == "" && optional {
+			return "", true
+		}
+		if value == "" {
+			fmt.Fprintln(s.out, "  参数不能为空")
+			continue
+		}
+		if _, err := shared.CoerceValue(param, value); err != nil {
+			fmt.Fprintln(s.out, " ", err)
+			continue
+		}
+		return value, true
+	}
+}
+
+func (s *shell) printError(err error) {
+	red := color.New(color.FgRed).SprintFunc()
+	fmt.Fprintf(s.errOut, "%s [%s] %v\n", red("错误:"), shared.CodeOf(err), err)
+}
+
+// printParams 输出参数或选项的说明
+func printParams(w io.Writer, title string, params []shared.ParamSpec) {
+	if len(params) == 0 {
+		return
+	}
+	fmt.Fprintln(w, color.New(color.FgBlue).Sprint(title))
+	t := &table{}
+	for _, param := range params {
+		t.add("  "+param.String(), param.Description)
+	}
+	t.write(w)
+}
+
+// signature 返回方法的可读签名, 例如 "AddDays(date time.Time, days int64)"
+func signature(method string, params []shared.ParamSpec) string {
+	return method + "(" + shared.JoinParams(params) + ")"
+}
+
+// splitWords 按空白拆分命令行, 单引号和双引号内的空白不拆分
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "引号 %c 没有闭合", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// shellCompleter 按已加载插件的ABI补全命令、<插件>.<方法> 和 --选项=
+type shellCompleter struct {
+	pm *shared.PluginManager
+}
+
+func (c *shellCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	head := string(line[:pos])
+	current := head[strings.LastIndexAny(head, " \t")+1:]
+	previous := strings.Fields(head[:len(head)-len(current)])
+
+	var candidates []string
+	switch {
+	case len(previous) == 0:
+		for _, command := range shellCommands {
+			candidates = append(candidates, command+" ")
+		}
+		candidates = append(candidates, c.targets(current)...)
+	case len(previous) == 1 && previous[0] == "help":
+		candidates = c.targets(current)
+	case strings.HasPrefix(current, "-"):
+		candidates = c.options(previous[0])
+	}
+
+	var completions [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			completions = append(completions, []rune(candidate[len(current):]))
+		}
+	}
+	return completions, len([]rune(current))
+}
+
+// targets 没有输入 "." 时补全插件名, 否则补全该插件的方法
+func (c *shellCompleter) targets(current string) []string {
+	pluginName, _, ok := strings.Cut(current, ".")
+	if !ok {
+		var names []string
+		for _, name := range c.pm.Names() {
+			names = append(names, name+".")
+		}
+		return names
+	}
+	abi, found := c.pm.ABI(pluginName)
+	if !found {
+		return nil
+	}
+	var methods []string
+	for method := range abi.Methods {
+		methods = append(methods, pluginName+"."+method+" ")
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// options 补全方法的关键字选项
+func (c *shellCompleter) options(target string) []string {
+	pluginName, method, _ := strings.Cut(target, ".")
+	abi, ok := c.pm.ABI(pluginName)
+	if !ok {
+		return nil
+	}
+	var options []string
+	for _, option := range abi.Methods[method].Options {
+		options = append(options, "--"+option.Name+"=")
+	}
+	return options
+}
+
+// defaultHistoryFile 用户主目录下的历史文件, 无法确定主目录时不保存历史
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".plugin-cli_history")
+}
+
+func init() {
+	shellCmd.Flags().StringVar(&historyFile, "history", defaultHistoryFile(), "命令历史文件, 为空时不保存")
+	rootCmd.AddCommand(shellCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scriptReader 按顺序返回预先给出的输入行, 读完后返回 io.EOF
+type scriptReader struct {
+	lines   []string
+	prompts []string // 每次读取时的提示符
+	history []string
+	prompt  string
+}
+
+func (r *scriptReader) Readline() (string, error) {
+	if len(r.lines) == 0 {
+		return "", io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	r.prompts = append(r.prompts, r.prompt)
+	return line, nil
+}
+
+func (r *scriptReader) SetPrompt(prompt string) { r.prompt = prompt }
+
+func (r *scriptReader) SaveHistory(line string) error {
+	r.history = append(r.history, line)
+	return nil
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr string
+	}{
+		{line: "calculator.Add 1 2", want: []string{"calculator.Add", "1", "2"}},
+		{line: "  help\tcalculator  ", want: []string{"help", "calculator"}},
+		{line: `string_utils.ToUpper "hello world"`, want: []string{"string_utils.ToUpper", "hello world"}},
+		{line: `a 'it"s' "x'y"`, want: []string{"a", `it"s`, "x'y"}},
+		{line: `a "" b`, want: []string{"a", "", "b"}},
+		{line: `a pre"fix suf"fix`, want: []string{"a", "prefix suffix"}},
+		{line: `a "open`, wantErr: `引号 " 没有闭合`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := splitWords(tt.line)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("splitWords 返回 %v, 期望包含 %q 的错误", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWords = %q, %v, 期望 %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// runShell 以 lines 为输入运行一次会话, 返回标准输出、错误输出和读取器
+func runShell(t *testing.T, s *shell, lines ...string) (string, string, *scriptReader) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	reader := &scriptReader{lines: lines}
+	s.rl, s.out, s.errOut = reader, &out, &errOut
+	s.run()
+	return out.String(), errOut.String(), reader
+}
+
+func TestShell(t *testing.T) {
+	s := &shell{pm: startTestPlugins(t)}
+
+	tests := []struct {
+		name    string
+		lines   []string
+		wantOut []string // 标准输出应包含的内容
+		wantErr []string // 错误输出应包含的内容
+	}{
+		{name: "帮助", lines: []string{"help"}, wantOut: []string{"help <插件>.<方法>", "exit"}},
+		{name: "插件帮助", lines: []string{"help calculator"},
+			wantOut: []string{"calculator 1.0.0", "Add(a float64, b float64)", "Adds two numbers."}},
+		{name: "方法帮助", lines: []string{"help calculator.Divide"},
+			wantOut: []string{"calculator.Divide(a float64, b float64) → float64", "b must not be zero", "参数:", "Right operand"}},
+		{name: "帮助中的插件不存在", lines: []string{"help missing"}, wantErr: []string{"[NotFound] 插件未加载 - missing"}},
+		{name: "帮助中的方法不存在", lines: []string{"help calculator.Missing"}, wantErr: []string{"[NotFound] 方法不存在 - calculator.Missing"}},
+		{name: "调用", lines: []string{"calculator.Add 1.5 2"}, wantOut: []string{"calculator.Add(1.5, 2)", "3.5", "float64"}},
+		{name: "引号中的空白", lines: []string{`string_utils.ToUpper "hello world"`}, wantOut: []string{"HELLO WORLD"}},
+		{name: "未知命令", lines: []string{"frobnicate"}, wantErr: []string{"[InvalidArgument] 未知命令 frobnicate"}},
+		{name: "插件不存在", lines: []string{"missing.Add 1 2"}, wantErr: []string{"[NotFound] 插件未加载 - missing"}},
+		{name: "方法不存在", lines: []string{"calculator.Missing"}, wantErr: []string{"[NotFound] 方法不存在 - calculator.Missing"}},
+		{name: "插件返回的错误", lines: []string{"calculator.Divide 1 0"}, wantErr: []string{"[InvalidArgument]"}},
+		{name: "参数类型错误", lines: []string{"calculator.Add x 2"}, wantErr: []string{"[InvalidArgument]"}},
+		{name: "引号没有闭合", lines: []string{`string_utils.ToUpper "abc`}, wantErr: []string{`[InvalidArgument] 引号 " 没有闭合`}},
+		{name: "出错后继续读取", lines: []string{"frobnicate", "calculator.Multiply 3 4"},
+			wantOut: []string{"12"}, wantErr: []string{"未知命令 frobnicate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut, _ := runShell(t, s, tt.lines...)
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out, want) {
+					t.Errorf("输出中没有 %q:\n%s", want, out)
+				}
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(errOut, want) {
+					t.Errorf("错误输出中没有 %q:\n%s", want, errOut)
+				}
+			}
+			if len(tt.wantErr) == 0 && errOut != "" {
+				t.Errorf("不应输出错误, 实际为:\n%s", errOut)
+			}
+		})
+	}
+}
+
+// 省略参数时按参数类型逐个提示, 类型不符的输入重新读取
+func TestShellPrompt(t *testing.T) {
+	s := &shell{pm: startTestPlugins(t)}
+	out, errOut, reader := runShell(t, s, "calculator.Add", "x", "1", "", "2", "calculator.Subtract")
+	if errOut != "" {
+		t.Errorf("不应输出错误, 实际为:\n%s", errOut)
+	}
+	if !strings.Contains(out, "calculator.Add(1, 2)") || !strings.Contains(out, "3") {
+		t.Errorf("提示输入的参数没有用于调用:\n%s", out)
+	}
+	if !strings.Contains(out, "参数不能为空") {
+		t.Errorf("必填参数留空时应提示:\n%s", out)
+	}
+	wantPrompts := []string{"", "  a float64 (必填): ", "  a float64 (必填): ", "  b float64 (必填): ", "  b float64 (必填): ", "plugin> "}
+	if !reflect.DeepEqual(reader.prompts, wantPrompts) {
+		t.Errorf("提示符为 %q, 期望 %q", reader.prompts, wantPrompts)
+	}
+	// 输入在提示过程中结束时取消调用
+	if strings.Contains(out, "calculator.Subtract(") {
+		t.Errorf("输入结束后不应调用 Subtract:\n%s", out)
+	}
+	if want := []string{"calculator.Add", "calculator.Subtract"}; !reflect.DeepEqual(reader.history, want) {
+		t.Errorf("历史记录为 %q, 期望 %q", reader.history, want)
+	}
+}
+
+// exit 之后的输入不再读取
+func TestShellExit(t *testing.T) {
+	s := &shell{pm: startTestPlugins(t)}
+	out, _, reader := runShell(t, s, "", "exit", "calculator.Add 1 2")
+	if strings.Contains(out, "calculator.Add(") || len(reader.lines) != 1 {
+		t.Errorf("exit 后仍在读取输入, 剩余 %q, 输出:\n%s", reader.lines, out)
+	}
+	if !strings.Contains(out, "已加载插件: calculator, string_utils") {
+		t.Errorf("开始时应列出已加载的插件:\n%s", out)
+	}
+	if want := []string{"exit"}; !reflect.DeepEqual(reader.history, want) {
+		t.Errorf("空行不应计入历史, 历史记录为 %q", reader.history)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"go-plugin-demo/src/shared"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	signKeyFile string
+	signName    string
+)
+
+var signCmd = &cobra.Command{
+	Use:   "sign [插件路径...]",
+	Short: "签名插件",
+	Long: `用发布者私钥对构建好的插件签名，并在插件旁写入签名描述文件 <插件路径>` + shared.SignatureManifestSuffix + `。
+签名同时覆盖插件名，插件名默认为文件名（去掉 .exe 后缀），与插件目录中的命名一致；
+配置文件中插件的 name 与文件名不同时用 --name 指定。
+宿主加载插件前会校验其中的SHA-256校验和，并用配置文件 security.trusted_keys 中的公钥验证签名`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if signName != "" && len(args) > 1 {
+			return shared.NewError(shared.CodeInvalidArgument, "--name 只能用于签名单个插件")
+		}
+		cmd.SilenceUsage = true
+
+		red := color.New(color.FgRed).SprintFunc()
+		green := color.New(color.FgGreen).SprintFunc()
+		blue := color.New(color.FgBlue).SprintFunc()
+
+		key, err := shared.ReadPrivateKey(signKeyFile)
+		if err != nil {
+			return err
+		}
+
+		// 单个插件签名失败不影响其他插件, 最后汇总为错误
+		failed := 0
+		for _, path := range args {
+			name := signName
+			if name == "" {
+				name = shared.PluginName(path)
+			}
+			manifest, err := shared.SignPlugin(name, path, key)
+			if err == nil {
+				err = shared.WriteSignatureManifest(shared.SignatureManifestPath(path), manifest)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, red("签名失败:"), path, err)
+				failed++
+				continue
+			}
+
+			fmt.Printf("%s %s\n", blue("已签名:"), green(path))
+			fmt.Printf("  name:      %s\n", manifest.Name)
+			fmt.Printf("  sha256:    %s\n", manifest.SHA256)
+			fmt.Printf("  signature: %s\n", manifest.Signature)
+			fmt.Printf("  key_id:    %s\n", manifest.KeyID)
+			fmt.Printf("  签名描述:  %s\n", shared.SignatureManifestPath(path))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d/%d 个插件签名失败", failed, len(args))
+		}
+		return nil
+	},
+}
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen [密钥名]",
+	Short: "生成签名密钥",
+	Long: `生成插件发布者的ed25519密钥对，私钥写入 <密钥名>.key，公钥写入 <密钥名>.pub。
+将公钥加入配置文件的 security.trusted_keys 后，宿主只接受由该私钥签名的插件`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		green := color.New(color.FgGreen).SprintFunc()
+		blue := color.New(color.FgBlue).SprintFunc()
+
+		pub, priv, err := shared.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("生成密钥失败: %w", err)
+		}
+
+		keyPath, pubPath := args[0]+".key", args[0]+".pub"
+		if err := writeKeyPair(keyPath, pubPath, pub, priv); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s %s\n", blue("私钥:"), green(keyPath))
+		fmt.Printf("%s %s\n", blue("公钥:"), green(pubPath))
+		fmt.Printf("%s %s\n", blue("key_id:"), shared.KeyID(pub))
+		fmt.Printf("%s %s\n", blue("trusted_keys:"), shared.EncodePublicKey(pub))
+		return nil
+	},
+}
+
+func writeKeyPair(keyPath, pubPath string, pub ed25519.PublicKey, priv ed25519.PrivateKey) error {
+	// 不覆盖已有的私钥, 避免已发布插件的签名失效
+	if _, err := os.Stat(keyPath); err == nil {
+		return fmt.Errorf("私钥文件 %s 已存在", keyPath)
+	}
+	if err := shared.WritePrivateKey(keyPath, priv); err != nil {
+		return err
+	}
+	if err := os.WriteFile(pubPath, []byte(shared.EncodePublicKey(pub)+"\n"), 0644); err != nil {
+		return fmt.Errorf("写入公钥失败: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	signCmd.Flags().StringVarP(&signKeyFile, "key", "k", "", "发布者私钥文件 (由 keygen 生成)")
+	signCmd.MarkFlagRequired("key")
+	signCmd.Flags().StringVarP(&signName, "name", "n", "", "签名的插件名, 默认为文件名")
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(keygenCmd)
+}
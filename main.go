@@ -0,0 +1,12 @@
+package main
+
+import (
+	"go-plugin-demo/cmd"
+	"go-plugin-demo/src/shared"
+)
+
+func main() {
+	// 需要 rlimit 的插件经由宿主自身启动, 这时只设置 rlimit 并 exec 插件
+	shared.RunSandboxExec()
+	cmd.Execute()
+}
@@ -2,232 +2,189 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
-	"os/exec"
-	"time"
+	"sort"
+	"strconv"
+	"strings"
 
-	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/go-plugin"
-
-	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
-	calculator "go-plugin-demo/src/plugins/calculator/shared"
+	"go-plugin-demo/src/shared"
 )
 
-// 插件配置结构
-type PluginConfig struct {
-	Name      string                 `json:"name"`
-	Path      string                 `json:"path"`
-	Handshake plugin.HandshakeConfig `json:"handshake"`
-}
-
-// 配置文件结构
-type Config struct {
-	Plugins []PluginConfig `json:"plugins"`
-}
+// defaultConfigPath 未在命令行指定配置文件时使用的配置文件
+const defaultConfigPath = "config/plugins.json"
 
 func main() {
-	// Create an hclog.Logger
-	logger := hclog.New(&hclog.LoggerOptions{
-		Name:   "plugin",
-		Output: os.Stdout,
-		Level:  hclog.Debug,
-	})
-
-	// 1. 读取配置文件
-	configFile, err := os.ReadFile("config/plugins.json")
-	if err != nil {
-		logger.Error("读取插件配置文件失败:", err)
-		return
-	}
+	// 需要 rlimit 的插件经由宿主自身启动, 这时只设置 rlimit 并 exec 插件
+	shared.RunSandboxExec()
 
-	var config Config
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		logger.Error("解析插件配置失败:", err)
-		return
+	configPath := defaultConfigPath
+	if len(os.Args) > 1 {
+		configPath = os.Args[1]
 	}
 
-	// // 2. 初始化插件管理器
-	pm := NewPluginManager()
+	// 1. 按配置文件加载插件, 单个插件加载失败不影响其他插件
+	pm := shared.NewPluginManager()
 	defer pm.UnloadAll()
-	//
-	// // 3. 加载所有插件
-	// for _, pluginConfig := range config.Plugins {
-	// 	err := pm.LoadPlugins(&pluginConfig)
-	// 	if err != nil {
-	// 		log.Fatal(pluginConfig.Name, " 加载插件失败: ", err)
-	// 		continue
-	// 	}
-	// }
-
-	handshake := plugin.HandshakeConfig{
-		ProtocolVersion:  1,
-		MagicCookieKey:   "DYNAMIC_PLUGIN_CALCULATOR",
-		MagicCookieValue: "calculator",
-	}
 
-	// pluginMap is the map of plugins we can dispense.
-	pluginMap := map[string]plugin.Plugin{
-		"calculator": &calculator.CalculatorPlugin{},
+	if err := pm.LoadFromConfig(configPath); err != nil {
+		for _, e := range shared.SplitErrors(err) {
+			log.Printf("加载插件失败: %v", e)
+		}
 	}
-
-	// We're a host! Start by launching the plugin process.
-	client := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: handshake,
-		Plugins:         pluginMap,
-		Cmd:             exec.Command("./bin/plugins/calculator"),
-		Logger:          logger,
-	})
-
-	rpcClient, err := client.Client()
-	if err != nil {
-		logger.Error(fmt.Sprintf("RPC连接失败: %v", err))
+	if len(pm.Names()) == 0 {
+		log.Printf("没有可用的插件, 请检查配置文件 %s", configPath)
 		return
 	}
 
-	// 3. 获取插件实例
-	raw, err := rpcClient.Dispense("calculator")
-	if err != nil {
-		logger.Error(fmt.Sprintf("获取插件实例失败: %v", err))
-	}
-
-	fmt.Printf("插件实例: %v\n", raw)
-
-	pm.plugins["calculator"] = client
-
-	handshake = dynamic_plugin_shared.GenHandShakeConfig("calculator")
+	// 2. 交互式菜单, 由已加载插件的ABI生成
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		names := pm.Names()
+		fmt.Println("\n请选择要测试的插件:")
+		for i, name := range names {
+			version := ""
+			if abi, ok := pm.ABI(name); ok {
+				version = " v" + abi.Version
+			}
+			fmt.Printf("%d. %s%s\n", i+1, name, version)
+		}
+		fmt.Printf("%d. 退出\n", len(names)+1)
 
-	pluginMap = map[string]plugin.Plugin{
-		"date_utils": &dynamic_plugin_shared.DynamicPlugin{},
+		choice, ok := readChoice(reader, len(names)+1)
+		if !ok {
+			return
+		}
+		if choice == len(names)+1 {
+			return
+		}
+		if !methodMenu(reader, pm, names[choice-1]) {
+			return
+		}
 	}
+}
 
-	client = plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: handshake,
-		Plugins:         pluginMap,
-		Cmd:             exec.Command("./bin/plugins/date_utils"),
-		Logger:          logger,
-	})
-
-	rpcClient, err = client.Client()
-	if err != nil {
-		logger.Error(fmt.Sprintf("RPC连接失败: %v", err))
-		return
+// methodMenu 列出插件的方法并调用选中的方法, 输入结束时返回 false
+func methodMenu(reader *bufio.Reader, pm *shared.PluginManager, pluginName string) bool {
+	abi, ok := pm.ABI(pluginName)
+	if !ok {
+		fmt.Printf("插件 %s 未加载\n", pluginName)
+		return true
 	}
-
-	raw, err = rpcClient.Dispense("date_utils")
-	if err != nil {
-		logger.Error(fmt.Sprintf("获取插件实例失败: %v", err))
+	methods := make([]string, 0, len(abi.Methods))
+	for method := range abi.Methods {
+		methods = append(methods, method)
 	}
+	sort.Strings(methods)
 
-	fmt.Printf("插件实例: %v\n", raw)
-
-	pm.plugins["date_utils"] = client
-
-	// 4. 交互式菜单
-	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Println("\n请选择要测试的插件:")
-		fmt.Println("1. 计算器插件")
-		fmt.Println("2. 字符串插件")
-		fmt.Println("3. 日期插件")
-		fmt.Println("4. 退出")
-		fmt.Print("请输入选项: ")
-
-		input, _ := reader.ReadString('\n')
-		switch input {
-		case "1\n":
-			if _, ok := pm.plugins["calculator"]; ok {
-				rpcClient, _ := pm.plugins["calculator"].Client()
-				// 3. 获取插件实例
-				raw, err := rpcClient.Dispense("calculator")
-				if err != nil {
-					logger.Error(fmt.Sprintf("获取插件实例失败: %v", err))
-				}
-				calc := raw.(calculator.Calculator)
-				res, err := calc.Add(1, 2)
-				fmt.Printf("计算结果: %f\nerr: %v", res, err)
-				res, err = calc.Subtract(1, 2)
-				fmt.Printf("计算结果: %f\nerr: %v", res, err)
-				res, err = calc.Multiply(1, 2)
-				fmt.Printf("计算结果: %f\nerr: %v", res, err)
-				res, err = calc.Divide(1, 2)
-				fmt.Printf("计算结果: %f\nerr: %v", res, err)
-
-			} else {
-				fmt.Println("计算器插件未加载")
-			}
-		//case "2\n":
-		//	if _, ok := pm.plugins["string_utils"]; ok {
-		//		testStringUtils(pm)
-		//	} else {
-		//		fmt.Println("字符串插件未加载")
-		//	}
-		case "3\n":
-			if _, ok := pm.plugins["date_utils"]; ok {
-				rpcClient, _ := pm.plugins["date_utils"].Client()
-				// 3. 获取插件实例
-				raw, err := rpcClient.Dispense("date_utils")
-				if err != nil {
-					logger.Error(fmt.Sprintf("获取插件实例失败: %v", err))
-				}
-				dp := raw.(dynamic_plugin_shared.DynamicPluginInterface)
-				n := time.Now().Format(time.RFC3339)
-				fmt.Println("当前时间:", n)
-				res, err := dp.Invoke("AddDays", []interface{}{n, 5}, []interface{}{})
-				fmt.Printf("计算结果: %v\nerr: %v", res, err)
-			} else {
-				fmt.Println("日期插件未加载")
+		fmt.Printf("\n%s 的方法:\n", pluginName)
+		for i, method := range methods {
+			spec := abi.Methods[method]
+			fmt.Printf("%d. %s → %s", i+1, signature(method, spec), spec.Returns)
+			if spec.Help != "" {
+				fmt.Printf("  %s", spec.Help)
 			}
-		case "4\n":
-			return
-		default:
-			fmt.Println("无效选项")
+			fmt.Println()
+		}
+		fmt.Printf("%d. 返回\n", len(methods)+1)
+
+		choice, ok := readChoice(reader, len(methods)+1)
+		if !ok {
+			return false
+		}
+		if choice == len(methods)+1 {
+			return true
+		}
+		if !invokeMethod(reader, pm, pluginName, methods[choice-1], abi.Methods[methods[choice-1]]) {
+			return false
 		}
 	}
 }
 
-/*
-func testCalculator(pm *PluginManager) {
-	fmt.Println("\n测试计算器插件:")
-	if sum, err := pm.Invoke("calculator", "Add", 5, 3); err == nil {
-		fmt.Println("5 + 3 =", sum)
-	}
-	if diff, err := pm.Invoke("calculator", "Subtract", 5, 3); err == nil {
-		fmt.Println("5 - 3 =", diff)
-	}
-	if product, err := pm.Invoke("calculator", "Multiply", 5, 3); err == nil {
-		fmt.Println("5 * 3 =", product)
+// invokeMethod 按参数描述逐个读取参数和选项, 调用方法并打印结果, 输入结束时返回 false
+func invokeMethod(reader *bufio.Reader, pm *shared.PluginManager, pluginName, method string, spec shared.MethodSpec) bool {
+	var raw []string
+	for _, param := range spec.Params {
+		hint := "必填"
+		if param.Optional {
+			hint = "可留空"
+		}
+		value, ok := prompt(reader, fmt.Sprintf("%s (%s): ", param, hint))
+		if !ok {
+			return false
+		}
+		// 留空的可选参数及其后的参数都不传, 由 CoerceArgs 补齐默认值
+		if value == "" && param.Optional {
+			break
+		}
+		raw = append(raw, value)
 	}
-	if quotient, err := pm.Invoke("calculator", "Divide", 5, 3); err == nil {
-		fmt.Printf("5 / 3 = %.2f\n", quotient)
+	args, err := shared.CoerceArgs(spec, raw)
+	if err != nil {
+		fmt.Println("参数错误:", err)
+		return true
 	}
-}
 
-func testStringUtils(pm *PluginManager) {
-	fmt.Println("\n测试字符串插件:")
-	if result, err := pm.Invoke("string_utils", "Reverse", "hello"); err == nil {
-		fmt.Println("Reverse('hello') =", result)
+	rawOptions := make(map[string]string)
+	for _, option := range spec.Options {
+		value, ok := prompt(reader, fmt.Sprintf("选项 %s (可留空): ", option))
+		if !ok {
+			return false
+		}
+		if value != "" {
+			rawOptions[option.Name] = value
+		}
 	}
-	if result, err := pm.Invoke("string_utils", "ToUpper", "hello"); err == nil {
-		fmt.Println("ToUpper('hello') =", result)
+	options, err := shared.CoerceOptions(spec, rawOptions)
+	if err != nil {
+		fmt.Println("选项错误:", err)
+		return true
 	}
-	if result, err := pm.Invoke("string_utils", "ToCamel", "hello world"); err == nil {
-		fmt.Println("ToCamel('hello world') =", result)
+
+	result, err := pm.InvokeOptions(context.Background(), pluginName, method, args, options)
+	if err != nil {
+		fmt.Printf("调用失败 [%s]: %v\n", shared.CodeOf(err), err)
+		return true
 	}
+	fmt.Printf("%s.%s 返回: %v\n", pluginName, method, result)
+	return true
 }
 
-func testDateUtils(pm *PluginManager) {
-	fmt.Println("\n测试日期插件:")
-	now := time.Now()
-	if result, err := pm.Invoke("date_utils", "AddDays", now, 7); err == nil {
-		fmt.Printf("AddDays(%v, 7) = %v\n", now.Format("2006-01-02"), result.(time.Time).Format("2006-01-02"))
+// signature 返回方法的可读签名, 例如 "AddDays(date time.Time, days int)"
+func signature(method string, spec shared.MethodSpec) string {
+	params := make([]string, len(spec.Params))
+	for i, param := range spec.Params {
+		params[i] = param.String()
 	}
-	if result, err := pm.Invoke("date_utils", "Format", now, "2006-01-02 15:04:05"); err == nil {
-		fmt.Println("Format(now) =", result)
+	return method + "(" + strings.Join(params, ", ") + ")"
+}
+
+// readChoice 读取 1 到 max 之间的选项, 输入无效时重新读取, 输入结束时返回 false
+func readChoice(reader *bufio.Reader, max int) (int, bool) {
+	for {
+		input, ok := prompt(reader, "请输入选项: ")
+		if !ok {
+			return 0, false
+		}
+		choice, err := strconv.Atoi(input)
+		if err == nil && choice >= 1 && choice <= max {
+			return choice, true
+		}
+		fmt.Println("无效选项")
 	}
-	if result, err := pm.Invoke("date_utils", "Between", now, now.AddDate(0, 0, 14)); err == nil {
-		fmt.Println("Between(now, now+14d) =", result, "days")
+}
+
+// prompt 打印提示并读取一行输入, 输入结束时返回 false
+func prompt(reader *bufio.Reader, label string) (string, bool) {
+	fmt.Print(label)
+	input, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || input == "") {
+		fmt.Println()
+		return "", false
 	}
+	return strings.TrimSpace(input), true
 }
-*/
@@ -0,0 +1,55 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"time"
+)
+
+// ContextInvoker 插件可选实现的带上下文调用接口
+// 实现后宿主的截止时间和取消会传给插件函数, 插件可以据此提前停止工作
+type ContextInvoker interface {
+	InvokeContext(ctx context.Context, method string, args []interface{}, options Options) (interface{}, error)
+}
+
+// InvokeContext 带上下文调用插件
+// impl 实现了 ContextInvoker 时直接传入上下文; 否则上下文结束时立即返回, 插件函数在后台运行到结束
+func InvokeContext(ctx context.Context, impl DynamicPluginInterface, method string, args []interface{}, options Options) (interface{}, error) {
+	if invoker, ok := impl.(ContextInvoker); ok {
+		return invoker.InvokeContext(ctx, method, args, options)
+	}
+	return AwaitContext(ctx, func() (interface{}, error) {
+		return impl.Invoke(method, args, options)
+	})
+}
+
+// AwaitContext 在后台执行 call, 上下文先结束时返回上下文的错误
+func AwaitContext(ctx context.Context, call func() (interface{}, error)) (interface{}, error) {
+	if ctx.Done() == nil {
+		return call()
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DeadlineContext 由RPC请求中携带的截止时间恢复上下文, 零值表示不限制
+func DeadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
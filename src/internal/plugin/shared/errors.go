@@ -0,0 +1,134 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/rpc"
+	"strings"
+)
+
+// ErrorCode 错误分类, 在RPC上以字符串传递
+type ErrorCode string
+
+const (
+	CodeNotFound        ErrorCode = "NotFound"        // 插件或方法不存在
+	CodeInvalidArgument ErrorCode = "InvalidArgument" // 参数或选项无效
+	CodeInternal        ErrorCode = "Internal"        // 插件内部错误, 未分类的错误都归为此类
+	CodeUnavailable     ErrorCode = "Unavailable"     // 插件未启动、已退出或正在重启
+	CodeTimeout         ErrorCode = "Timeout"         // 调用超时
+)
+
+// Error 错误码本身也是错误, 可以作为 errors.Is 的目标按错误码判断, 例如 errors.Is(err, CodeNotFound)
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// Error 带错误码的结构化错误
+// 插件返回的 Error 经 net/rpc 或 gRPC 传给宿主后保持错误码、消息和详情不变
+type Error struct {
+	Code    ErrorCode         `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+	cause   error             // 原始错误, 只在本进程内有效, 不随RPC传递
+}
+
+// NewError 创建指定错误码的错误
+func NewError(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WrapError 以 err 的文本为消息创建指定错误码的错误, errors.Is/As 仍可以找到 err
+func WrapError(code ErrorCode, err error) *Error {
+	return &Error{Code: code, Message: err.Error(), cause: err}
+}
+
+// WithDetail 添加一项详情并返回错误本身
+func (e *Error) WithDetail(key, value string) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]string)
+	}
+	e.Details[key] = value
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 目标为 ErrorCode 或 *Error 时, 错误码相同即视为匹配
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return t == e.Code
+	case *Error:
+		return t.Code == e.Code
+	}
+	return false
+}
+
+// CodeOf 返回错误的错误码
+// 链上没有 Error 时, 上下文超时为 Timeout, 上下文取消和连接关闭为 Unavailable, 其他为 Internal
+func CodeOf(err error) ErrorCode {
+	var e *Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &e):
+		return e.Code
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, rpc.ErrShutdown):
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// AsError 将任意错误转换为 Error, 错误码由 CodeOf 确定
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return WrapError(CodeOf(err), err)
+}
+
+// rpcErrorPrefix net/rpc 只传递错误文本, 结构化错误以此前缀加JSON的形式编码
+const rpcErrorPrefix = "dynamic-plugin-error:"
+
+// EncodeRPCError 插件端将错误编码为 net/rpc 可以传递的形式
+func EncodeRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	data, jsonErr := json.Marshal(AsError(err))
+	if jsonErr != nil {
+		return err
+	}
+	return errors.New(rpcErrorPrefix + string(data))
+}
+
+// DecodeRPCError 宿主端还原 EncodeRPCError 编码的错误, 其他错误原样返回
+func DecodeRPCError(err error) error {
+	serverErr, ok := err.(rpc.ServerError)
+	if !ok || !strings.HasPrefix(string(serverErr), rpcErrorPrefix) {
+		return err
+	}
+	var e Error
+	if json.Unmarshal([]byte(strings.TrimPrefix(string(serverErr), rpcErrorPrefix)), &e) != nil {
+		return err
+	}
+	return &e
+}
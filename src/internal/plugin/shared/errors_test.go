@@ -0,0 +1,109 @@
+package dynamic_plugin_shared
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+)
+
+func TestErrorRoundTrip(t *testing.T) {
+	table, err := newFuncTable("calc", "1.0.0", map[string]DynamicFunc{
+		"Divide": Wrap(func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, NewError(CodeInvalidArgument, "除数不能为零").WithDetail("param", "b")
+			}
+			return a / b, nil
+		}, DynamicFunc{}),
+		"Lookup": Wrap(func(key string) (string, error) {
+			return "", NewError(CodeNotFound, "键 %s 不存在", key)
+		}, DynamicFunc{}),
+		"Fail":  Wrap(func() error { return errors.New("磁盘已满") }, DynamicFunc{}),
+		"Panic": Wrap(func() { panic("boom") }, DynamicFunc{}),
+		"Busy": Wrap(func() error {
+			return WrapError(CodeUnavailable, errors.New("稍后重试"))
+		}, DynamicFunc{}),
+	}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins := map[string]plugin.Plugin{"test": &DynamicPlugin{Impl: table}}
+
+	transports := map[string]func(t *testing.T) interface{}{
+		"net/rpc": func(t *testing.T) interface{} {
+			client, _ := plugin.TestPluginRPCConn(t, plugins, nil)
+			t.Cleanup(func() { client.Close() })
+			raw, err := client.Dispense("test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return raw
+		},
+		"gRPC": func(t *testing.T) interface{} {
+			client, _ := plugin.TestPluginGRPCConn(t, false, plugins)
+			t.Cleanup(func() { client.Close() })
+			raw, err := client.Dispense("test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return raw
+		},
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		args        []interface{}
+		wantCode    ErrorCode
+		wantMessage string // 为空时不检查
+		wantDetails map[string]string
+	}{
+		{name: "带详情的错误", method: "Divide", args: []interface{}{1.0, 0.0}, wantCode: CodeInvalidArgument,
+			wantMessage: "除数不能为零", wantDetails: map[string]string{"param": "b"}},
+		{name: "插件返回的错误码", method: "Lookup", args: []interface{}{"x"}, wantCode: CodeNotFound, wantMessage: "键 x 不存在"},
+		{name: "包装的错误", method: "Busy", wantCode: CodeUnavailable, wantMessage: "稍后重试"},
+		{name: "普通错误", method: "Fail", wantCode: CodeInternal, wantMessage: "磁盘已满"},
+		{name: "panic", method: "Panic", wantCode: CodeInternal},
+		{name: "参数类型不符", method: "Divide", args: []interface{}{"1", 2.0}, wantCode: CodeInvalidArgument},
+		{name: "参数个数不符", method: "Divide", args: []interface{}{1.0}, wantCode: CodeInvalidArgument},
+		{name: "方法不存在", method: "Missing", wantCode: CodeNotFound},
+	}
+	for transport, dispense := range transports {
+		t.Run(transport, func(t *testing.T) {
+			impl, ok := dispense(t).(DynamicPluginInterface)
+			if !ok {
+				t.Fatal("插件实例没有实现 DynamicPluginInterface")
+			}
+			if impl.Name() != "calc" || impl.Version() != "1.0.0" {
+				t.Errorf("插件报告的名称和版本为 %q %q, 期望 calc 1.0.0", impl.Name(), impl.Version())
+			}
+			if result, err := impl.Invoke("Divide", []interface{}{1.0, 4.0}, nil); err != nil || result != 0.25 {
+				t.Fatalf("Divide(1, 4) = %v, %v, 期望 0.25", result, err)
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					_, err := impl.Invoke(tt.method, tt.args, nil)
+					var e *Error
+					if !errors.As(err, &e) {
+						t.Fatalf("调用返回 %v (%T), 期望 *Error", err, err)
+					}
+					if e.Code != tt.wantCode || !errors.Is(err, tt.wantCode) || !errors.Is(err, &Error{Code: tt.wantCode}) {
+						t.Errorf("错误码为 %s, 期望 %s", e.Code, tt.wantCode)
+					}
+					if errors.Is(err, CodeTimeout) {
+						t.Errorf("错误码为 %s 的错误不应匹配 %s", e.Code, CodeTimeout)
+					}
+					if tt.wantMessage != "" && e.Message != tt.wantMessage {
+						t.Errorf("消息为 %q, 期望 %q", e.Message, tt.wantMessage)
+					}
+					if !reflect.DeepEqual(e.Details, tt.wantDetails) {
+						t.Errorf("详情为 %v, 期望 %v", e.Details, tt.wantDetails)
+					}
+				})
+			}
+		})
+	}
+}
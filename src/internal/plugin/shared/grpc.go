@@ -0,0 +1,319 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"time"
+
+	pb "go-plugin-demo/src/internal/plugin/shared/proto"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer 实现 plugin.GRPCPlugin, 插件以gRPC协议提供服务
+func (p *DynamicPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	pb.RegisterDynamicPluginServer(s, &DynamicPluginGRPCServer{Impl: p.Impl})
+	return nil
+}
+
+// GRPCClient 实现 plugin.GRPCPlugin, 宿主端返回gRPC客户端
+func (DynamicPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &DynamicPluginGRPCClient{client: pb.NewDynamicPluginClient(c)}, nil
+}
+
+type DynamicPluginGRPCClient struct {
+	client pb.DynamicPluginClient
+}
+
+func (c *DynamicPluginGRPCClient) Invoke(method string, args []interface{}, options Options) (interface{}, error) {
+	return c.InvokeContext(context.Background(), method, args, options)
+}
+
+// InvokeContext 上下文的截止时间和取消由gRPC传给插件
+func (c *DynamicPluginGRPCClient) InvokeContext(ctx context.Context, method string, args []interface{}, options Options) (interface{}, error) {
+	argValues, err := ToValues(args)
+	if err != nil {
+		return nil, NewError(CodeInvalidArgument, "参数无法编码: %v", err)
+	}
+	optionValues, err := options.toValues()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Invoke(ctx, &pb.InvokeRequest{
+		Method:  method,
+		Args:    valuesToProto(argValues),
+		Options: valueMapToProto(optionValues),
+	})
+	if err != nil {
+		// 上下文结束导致的失败返回上下文的错误, 便于调用方用 errors.Is 判断
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, errorFromStatus(err)
+	}
+	return valueFromProto(resp.Result).Interface(), nil
+}
+
+func (c *DynamicPluginGRPCClient) Help(method string) (string, error) {
+	resp, err := c.client.Help(context.Background(), &pb.HelpRequest{Method: method})
+	if err != nil {
+		return "", errorFromStatus(err)
+	}
+	return resp.Help, nil
+}
+
+func (c *DynamicPluginGRPCClient) Version() string {
+	resp, err := c.client.Version(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return "unknown"
+	}
+	return resp.Version
+}
+
+// Name 返回插件报告的名称, 调用失败时返回空字符串
+func (c *DynamicPluginGRPCClient) Name() string {
+	resp, err := c.client.Version(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return ""
+	}
+	return resp.Name
+}
+
+func (c *DynamicPluginGRPCClient) Exports() ([]FuncSpec, error) {
+	resp, err := c.client.GetABI(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	specs := make([]FuncSpec, len(resp.Funcs))
+	for i, f := range resp.Funcs {
+		specs[i] = FuncSpec{
+			Name:       f.Name,
+			Params:     paramsFromProto(f.Params),
+			Options:    paramsFromProto(f.Options),
+			Returns:    f.Returns,
+			Help:       f.Help,
+			HasArgs:    f.HasArgs,
+			HasOptions: f.HasOptions,
+			Idempotent: f.Idempotent,
+		}
+	}
+	return specs, nil
+}
+
+type DynamicPluginGRPCServer struct {
+	pb.UnimplementedDynamicPluginServer
+	Impl DynamicPluginInterface
+}
+
+func (s *DynamicPluginGRPCServer) Invoke(ctx context.Context, req *pb.InvokeRequest) (*pb.InvokeResponse, error) {
+	result, err := InvokeContext(ctx, s.Impl, req.Method, FromValues(valuesFromProto(req.Args)), optionsFromValues(valueMapFromProto(req.Options)))
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	value, err := ToValue(result)
+	if err != nil {
+		return nil, statusFromError(NewError(CodeInternal, "返回值无法编码: %v", err))
+	}
+	return &pb.InvokeResponse{Result: valueToProto(value)}, nil
+}
+
+func (s *DynamicPluginGRPCServer) Help(ctx context.Context, req *pb.HelpRequest) (*pb.HelpResponse, error) {
+	help, err := s.Impl.Help(req.Method)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &pb.HelpResponse{Help: help}, nil
+}
+
+func (s *DynamicPluginGRPCServer) Version(ctx context.Context, _ *emptypb.Empty) (*pb.VersionResponse, error) {
+	return &pb.VersionResponse{Version: s.Impl.Version(), Name: s.Impl.Name()}, nil
+}
+
+func (s *DynamicPluginGRPCServer) GetABI(ctx context.Context, _ *emptypb.Empty) (*pb.GetABIResponse, error) {
+	exports, err := s.Impl.Exports()
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	funcs := make([]*pb.FuncSpec, len(exports))
+	for i, f := range exports {
+		funcs[i] = &pb.FuncSpec{
+			Name:       f.Name,
+			Params:     paramsToProto(f.Params),
+			Options:    paramsToProto(f.Options),
+			Returns:    f.Returns,
+			Help:       f.Help,
+			HasArgs:    f.HasArgs,
+			HasOptions: f.HasOptions,
+			Idempotent: f.Idempotent,
+		}
+	}
+	return &pb.GetABIResponse{Funcs: funcs}, nil
+}
+
+func valueToProto(v Value) *pb.Value {
+	switch v.Kind {
+	case KindBool:
+		return &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: v.Bool}}
+	case KindInt:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: v.Int}}
+	case KindFloat:
+		return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: v.Float}}
+	case KindString:
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: v.Str}}
+	case KindBytes:
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: v.Bytes}}
+	case KindTimestamp:
+		return &pb.Value{Kind: &pb.Value_TimestampValue{TimestampValue: timestamppb.New(v.Time)}}
+	case KindDuration:
+		return &pb.Value{Kind: &pb.Value_DurationValue{DurationValue: durationpb.New(time.Duration(v.Int))}}
+	case KindList:
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.ListValue{Values: valuesToProto(v.List)}}}
+	case KindMap:
+		return &pb.Value{Kind: &pb.Value_MapValue{MapValue: &pb.MapValue{Values: valueMapToProto(v.Map)}}}
+	default:
+		return &pb.Value{}
+	}
+}
+
+func valueFromProto(v *pb.Value) Value {
+	switch k := v.GetKind().(type) {
+	case *pb.Value_BoolValue:
+		return Value{Kind: KindBool, Bool: k.BoolValue}
+	case *pb.Value_IntValue:
+		return Value{Kind: KindInt, Int: k.IntValue}
+	case *pb.Value_FloatValue:
+		return Value{Kind: KindFloat, Float: k.FloatValue}
+	case *pb.Value_StringValue:
+		return Value{Kind: KindString, Str: k.StringValue}
+	case *pb.Value_BytesValue:
+		return Value{Kind: KindBytes, Bytes: k.BytesValue}
+	case *pb.Value_TimestampValue:
+		return Value{Kind: KindTimestamp, Time: k.TimestampValue.AsTime()}
+	case *pb.Value_DurationValue:
+		return Value{Kind: KindDuration, Int: int64(k.DurationValue.AsDuration())}
+	case *pb.Value_ListValue:
+		return Value{Kind: KindList, List: valuesFromProto(k.ListValue.GetValues())}
+	case *pb.Value_MapValue:
+		return Value{Kind: KindMap, Map: valueMapFromProto(k.MapValue.GetValues())}
+	default:
+		return Value{Kind: KindNull}
+	}
+}
+
+func valuesToProto(values []Value) []*pb.Value {
+	out := make([]*pb.Value, len(values))
+	for i, v := range values {
+		out[i] = valueToProto(v)
+	}
+	return out
+}
+
+func valuesFromProto(values []*pb.Value) []Value {
+	out := make([]Value, len(values))
+	for i, v := range values {
+		out[i] = valueFromProto(v)
+	}
+	return out
+}
+
+func valueMapToProto(values map[string]Value) map[string]*pb.Value {
+	out := make(map[string]*pb.Value, len(values))
+	for k, v := range values {
+		out[k] = valueToProto(v)
+	}
+	return out
+}
+
+func valueMapFromProto(values map[string]*pb.Value) map[string]Value {
+	out := make(map[string]Value, len(values))
+	for k, v := range values {
+		out[k] = valueFromProto(v)
+	}
+	return out
+}
+
+func paramsToProto(params []Param) []*pb.Param {
+	out := make([]*pb.Param, len(params))
+	for i, p := range params {
+		out[i] = &pb.Param{
+			Name:        p.Name,
+			Type:        p.Type,
+			Optional:    p.Optional,
+			Default:     p.Default,
+			Enum:        p.Enum,
+			Description: p.Description,
+		}
+	}
+	return out
+}
+
+func paramsFromProto(params []*pb.Param) []Param {
+	if len(params) == 0 {
+		return nil
+	}
+	out := make([]Param, len(params))
+	for i, p := range params {
+		out[i] = Param{
+			Name:        p.Name,
+			Type:        p.Type,
+			Optional:    p.Optional,
+			Default:     p.Default,
+			Enum:        p.Enum,
+			Description: p.Description,
+		}
+	}
+	return out
+}
+
+// grpcCodes 错误码与gRPC状态码的对应关系
+var grpcCodes = map[ErrorCode]codes.Code{
+	CodeNotFound:        codes.NotFound,
+	CodeInvalidArgument: codes.InvalidArgument,
+	CodeInternal:        codes.Internal,
+	CodeUnavailable:     codes.Unavailable,
+	CodeTimeout:         codes.DeadlineExceeded,
+}
+
+// statusFromError 将错误转换为gRPC状态, 完整的 Error 作为状态详情返回
+func statusFromError(err error) error {
+	e := AsError(err)
+	code, ok := grpcCodes[e.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, e.Error())
+	if withDetails, detailErr := st.WithDetails(&pb.Error{
+		Code:    string(e.Code),
+		Message: e.Message,
+		Details: e.Details,
+	}); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// errorFromStatus 还原插件返回的 Error
+// 状态中没有 Error 详情时(例如连接断开, 或其他语言实现的插件)按状态码转换, 原始错误仍在错误链上
+func errorFromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if e, ok := detail.(*pb.Error); ok {
+			return &Error{Code: ErrorCode(e.Code), Message: e.Message, Details: e.Details}
+		}
+	}
+	for code, grpcCode := range grpcCodes {
+		if st.Code() == grpcCode {
+			return WrapError(code, err)
+		}
+	}
+	return err
+}
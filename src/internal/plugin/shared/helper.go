@@ -2,10 +2,13 @@ package dynamic_plugin_shared
 
 import "github.com/hashicorp/go-plugin"
 
-func GenHandShakeConfig(pluginName string) plugin.HandshakeConfig {
-	return plugin.HandshakeConfig{
-		ProtocolVersion:  1,
-		MagicCookieKey:   "DYNAMIC_PLUGIN_" + pluginName,
-		MagicCookieValue: pluginName,
-	}
+// HandshakeConfig Serve 使用的握手配置, 与插件名无关
+// 宿主不需要事先知道插件名就能启动插件, 连接后按插件通过 Name 报告的名称注册
+var HandshakeConfig = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "DYNAMIC_PLUGIN_SHARED",
+	MagicCookieValue: "dynamic_plugin_shared",
 }
+
+// PluginKey Serve 注册插件实现时使用的名称, 宿主以它 Dispense
+const PluginKey = "dynamic_plugin"
@@ -1,7 +1,10 @@
 package dynamic_plugin_shared
 
 type DynamicPluginInterface interface {
-	Invoke(method string, args, options []interface{}) (interface{}, error)
+	Invoke(method string, args []interface{}, options Options) (interface{}, error)
 	Help(method string) (string, error)
 	Version() string
+	// Name 插件的名称, 即传给 Serve 的 name
+	Name() string
+	Exports() ([]FuncSpec, error)
 }
@@ -0,0 +1,136 @@
+package dynamic_plugin_shared
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Options 按名称传递的关键字选项
+type Options map[string]interface{}
+
+var optionsType = reflect.TypeOf(Options(nil))
+
+// optionTypes 选项类型名对应的Go类型, 其他类型的值不做转换
+var optionTypes = map[string]reflect.Type{
+	"string":        reflect.TypeOf(""),
+	"bool":          reflect.TypeOf(false),
+	"int":           reflect.TypeOf(int(0)),
+	"int32":         reflect.TypeOf(int32(0)),
+	"int64":         reflect.TypeOf(int64(0)),
+	"uint32":        reflect.TypeOf(uint32(0)),
+	"uint64":        reflect.TypeOf(uint64(0)),
+	"float":         reflect.TypeOf(float64(0)),
+	"float64":       reflect.TypeOf(float64(0)),
+	"time.Time":     timeType,
+	"time.Duration": durationType,
+}
+
+// CheckOptions 按函数声明的选项检查调用方传入的选项
+// 未声明的选项和无效的值返回 CodeInvalidArgument; 值转换为声明的类型并检查允许的取值; 未传入的选项使用默认值, 没有默认值时省略
+func CheckOptions(specs []Param, options Options) (Options, error) {
+	declared := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		declared[spec.Name] = true
+	}
+	var unknown []string
+	for name := range options {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, NewError(CodeInvalidArgument, "未知选项 %v", unknown)
+	}
+
+	checked := make(Options, len(specs))
+	for _, spec := range specs {
+		v, ok := options[spec.Name]
+		if !ok {
+			if spec.Default == "" {
+				continue
+			}
+			v = spec.Default
+		}
+		value, err := convertOption(spec, v)
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "选项 %s: %v", spec.Name, err)
+		}
+		checked[spec.Name] = value
+	}
+	return checked, nil
+}
+
+// convertOption 将选项值转换为声明的类型, 字符串形式的值(例如默认值)按类型解析
+func convertOption(spec Param, v interface{}) (interface{}, error) {
+	if t, ok := optionTypes[spec.Type]; ok {
+		if s, isString := v.(string); isString {
+			parsed, err := parseScalar(t, s)
+			if err != nil {
+				return nil, fmt.Errorf("值 %q 不是有效的 %s", s, spec.Type)
+			}
+			v = parsed
+		}
+		rv, err := convertArg(v, t)
+		if err != nil {
+			return nil, err
+		}
+		v = rv.Interface()
+	}
+	if len(spec.Enum) > 0 {
+		s := fmt.Sprint(v)
+		for _, allowed := range spec.Enum {
+			if s == allowed {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("值 %q 不在允许的取值 %v 中", s, spec.Enum)
+	}
+	return v, nil
+}
+
+// parseScalar 解析字符串形式的基本类型值, 时间和时间长度由 convertArg 解析
+func parseScalar(t reflect.Type, s string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			return s, nil
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	}
+	return s, nil
+}
+
+func (o Options) toValues() (map[string]Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]Value, len(o))
+	for name, v := range o {
+		value, err := ToValue(v)
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "选项 %s 无法编码: %v", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func optionsFromValues(values map[string]Value) Options {
+	if len(values) == 0 {
+		return nil
+	}
+	options := make(Options, len(values))
+	for name, v := range values {
+		options[name] = v.Interface()
+	}
+	return options
+}
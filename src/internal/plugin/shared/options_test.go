@@ -0,0 +1,69 @@
+package dynamic_plugin_shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckOptions(t *testing.T) {
+	specs := []Param{
+		{Name: "layout", Type: "string", Default: "2006-01-02"},
+		{Name: "order", Type: "string", Enum: []string{"asc", "desc"}},
+		{Name: "limit", Type: "int", Default: "10"},
+		{Name: "ratio", Type: "float64"},
+		{Name: "strict", Type: "bool"},
+		{Name: "timeout", Type: "time.Duration"},
+		{Name: "since", Type: "time.Time"},
+		{Name: "extra", Type: "map[string]string"},
+	}
+	defaults := Options{"layout": "2006-01-02", "limit": 10}
+
+	tests := []struct {
+		name    string
+		options Options
+		want    Options // 省略 defaults 中的默认值
+		wantErr string  // 为空表示检查通过
+	}{
+		{name: "只有默认值", options: nil, want: Options{}},
+		{name: "覆盖默认值", options: Options{"limit": int64(3)}, want: Options{"limit": 3}},
+		{name: "字符串形式的值按类型解析", options: Options{"limit": "5", "ratio": "0.5", "strict": "true"},
+			want: Options{"limit": 5, "ratio": 0.5, "strict": true}},
+		{name: "时间和时长", options: Options{"timeout": "3s", "since": "2024-01-02"},
+			want: Options{"timeout": 3 * time.Second, "since": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{name: "整数转为浮点数", options: Options{"ratio": int64(2)}, want: Options{"ratio": 2.0}},
+		{name: "允许的取值", options: Options{"order": "desc"}, want: Options{"order": "desc"}},
+		{name: "未声明的类型不转换", options: Options{"extra": map[string]interface{}{"a": "b"}},
+			want: Options{"extra": map[string]interface{}{"a": "b"}}},
+		{name: "未知选项", options: Options{"color": "red", "bold": true}, wantErr: "未知选项 [bold color]"},
+		{name: "不允许的取值", options: Options{"order": "up"}, wantErr: "选项 order: 值 \"up\" 不在允许的取值"},
+		{name: "无效的整数", options: Options{"limit": "ten"}, wantErr: "选项 limit: 值 \"ten\" 不是有效的 int"},
+		{name: "类型不符", options: Options{"strict": int64(1)}, wantErr: "选项 strict"},
+		{name: "无效的时长", options: Options{"timeout": "soon"}, wantErr: "选项 timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckOptions(specs, tt.options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || CodeOf(err) != CodeInvalidArgument {
+					t.Fatalf("CheckOptions 返回 %v (%s), 期望包含 %q 的 %s", err, CodeOf(err), tt.wantErr, CodeInvalidArgument)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Options{}
+			for name, v := range defaults {
+				want[name] = v
+			}
+			for name, v := range tt.want {
+				want[name] = v
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("CheckOptions = %#v, 期望 %#v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: dynamic_plugin.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	DynamicPlugin_Invoke_FullMethodName  = "/dynamicplugin.DynamicPlugin/Invoke"
+	DynamicPlugin_Help_FullMethodName    = "/dynamicplugin.DynamicPlugin/Help"
+	DynamicPlugin_Version_FullMethodName = "/dynamicplugin.DynamicPlugin/Version"
+	DynamicPlugin_GetABI_FullMethodName  = "/dynamicplugin.DynamicPlugin/GetABI"
+)
+
+// DynamicPluginClient is the client API for DynamicPlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DynamicPluginClient interface {
+	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	Help(ctx context.Context, in *HelpRequest, opts ...grpc.CallOption) (*HelpResponse, error)
+	Version(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionResponse, error)
+	GetABI(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetABIResponse, error)
+}
+
+type dynamicPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDynamicPluginClient(cc grpc.ClientConnInterface) DynamicPluginClient {
+	return &dynamicPluginClient{cc}
+}
+
+func (c *dynamicPluginClient) Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error) {
+	out := new(InvokeResponse)
+	err := c.cc.Invoke(ctx, DynamicPlugin_Invoke_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dynamicPluginClient) Help(ctx context.Context, in *HelpRequest, opts ...grpc.CallOption) (*HelpResponse, error) {
+	out := new(HelpResponse)
+	err := c.cc.Invoke(ctx, DynamicPlugin_Help_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dynamicPluginClient) Version(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionResponse, error) {
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, DynamicPlugin_Version_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dynamicPluginClient) GetABI(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetABIResponse, error) {
+	out := new(GetABIResponse)
+	err := c.cc.Invoke(ctx, DynamicPlugin_GetABI_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DynamicPluginServer is the server API for DynamicPlugin service.
+// All implementations must embed UnimplementedDynamicPluginServer
+// for forward compatibility
+type DynamicPluginServer interface {
+	Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error)
+	Help(context.Context, *HelpRequest) (*HelpResponse, error)
+	Version(context.Context, *emptypb.Empty) (*VersionResponse, error)
+	GetABI(context.Context, *emptypb.Empty) (*GetABIResponse, error)
+	mustEmbedUnimplementedDynamicPluginServer()
+}
+
+// UnimplementedDynamicPluginServer must be embedded to have forward compatible implementations.
+type UnimplementedDynamicPluginServer struct {
+}
+
+func (UnimplementedDynamicPluginServer) Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Invoke not implemented")
+}
+func (UnimplementedDynamicPluginServer) Help(context.Context, *HelpRequest) (*HelpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Help not implemented")
+}
+func (UnimplementedDynamicPluginServer) Version(context.Context, *emptypb.Empty) (*VersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedDynamicPluginServer) GetABI(context.Context, *emptypb.Empty) (*GetABIResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetABI not implemented")
+}
+func (UnimplementedDynamicPluginServer) mustEmbedUnimplementedDynamicPluginServer() {}
+
+// UnsafeDynamicPluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DynamicPluginServer will
+// result in compilation errors.
+type UnsafeDynamicPluginServer interface {
+	mustEmbedUnimplementedDynamicPluginServer()
+}
+
+func RegisterDynamicPluginServer(s grpc.ServiceRegistrar, srv DynamicPluginServer) {
+	s.RegisterService(&DynamicPlugin_ServiceDesc, srv)
+}
+
+func _DynamicPlugin_Invoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DynamicPluginServer).Invoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DynamicPlugin_Invoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DynamicPluginServer).Invoke(ctx, req.(*InvokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DynamicPlugin_Help_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HelpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DynamicPluginServer).Help(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DynamicPlugin_Help_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DynamicPluginServer).Help(ctx, req.(*HelpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DynamicPlugin_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DynamicPluginServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DynamicPlugin_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DynamicPluginServer).Version(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DynamicPlugin_GetABI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DynamicPluginServer).GetABI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DynamicPlugin_GetABI_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DynamicPluginServer).GetABI(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DynamicPlugin_ServiceDesc is the grpc.ServiceDesc for DynamicPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DynamicPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dynamicplugin.DynamicPlugin",
+	HandlerType: (*DynamicPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Invoke",
+			Handler:    _DynamicPlugin_Invoke_Handler,
+		},
+		{
+			MethodName: "Help",
+			Handler:    _DynamicPlugin_Help_Handler,
+		},
+		{
+			MethodName: "Version",
+			Handler:    _DynamicPlugin_Version_Handler,
+		},
+		{
+			MethodName: "GetABI",
+			Handler:    _DynamicPlugin_GetABI_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dynamic_plugin.proto",
+}
@@ -0,0 +1,160 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+)
+
+// ServeOption 调整 Serve 的默认行为
+type ServeOption func(*serveConfig)
+
+type serveConfig struct {
+	logger      hclog.Logger
+	tlsProvider func() (*tls.Config, error)
+}
+
+// WithLogger 使用指定的日志记录器, 默认以JSON格式输出到标准错误, 由宿主转发
+func WithLogger(logger hclog.Logger) ServeOption {
+	return func(c *serveConfig) { c.logger = logger }
+}
+
+// WithTLSProvider 替换默认的 TLSProvider
+func WithTLSProvider(provider func() (*tls.Config, error)) ServeOption {
+	return func(c *serveConfig) { c.tlsProvider = provider }
+}
+
+// Serve 以 name 为插件名运行函数表中的函数, 直到宿主结束插件进程
+// 握手配置为与插件名无关的 HandshakeConfig, Invoke、Help、Version 和 Exports 都由函数表实现, Name 返回 name;
+// 调用前按函数声明检查关键字选项; 同时支持 net/rpc 和 gRPC, 宿主配置了TLS时使用 TLSProvider
+func Serve(name, version string, funcs map[string]DynamicFunc, opts ...ServeOption) {
+	config := serveConfig{tlsProvider: TLSProvider}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.logger == nil {
+		config.logger = hclog.New(&hclog.LoggerOptions{
+			Name:       name,
+			Level:      hclog.Trace,
+			Output:     os.Stderr,
+			JSONFormat: true,
+		})
+	}
+
+	impl, err := newFuncTable(name, version, funcs, config.logger)
+	if err != nil {
+		panic(fmt.Sprintf("插件 %s: %v", name, err))
+	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: HandshakeConfig,
+		Plugins: map[string]plugin.Plugin{
+			PluginKey: &DynamicPlugin{Impl: impl},
+		},
+		GRPCServer:  plugin.DefaultGRPCServer,
+		TLSProvider: config.tlsProvider,
+		Logger:      config.logger,
+	})
+}
+
+// funcTable 由函数表实现 DynamicPluginInterface
+type funcTable struct {
+	name    string
+	version string
+	funcs   map[string]DynamicFunc
+	logger  hclog.Logger
+}
+
+// newFuncTable 检查函数表, 函数名为空时使用表中的键
+func newFuncTable(name, version string, funcs map[string]DynamicFunc, logger hclog.Logger) (*funcTable, error) {
+	if name == "" {
+		return nil, fmt.Errorf("插件名不能为空")
+	}
+	if version == "" {
+		return nil, fmt.Errorf("版本号不能为空")
+	}
+	table := make(map[string]DynamicFunc, len(funcs))
+	for key, f := range funcs {
+		if f.Name == "" {
+			f.Name = key
+		}
+		if f.Name != key {
+			return nil, fmt.Errorf("函数表的键 %s 与函数名 %s 不一致", key, f.Name)
+		}
+		if f.Call == nil && f.CallContext != nil {
+			callContext := f.CallContext
+			f.Call = func(args []interface{}, options Options) (interface{}, error) {
+				return callContext(context.Background(), args, options)
+			}
+		}
+		if f.Call == nil {
+			return nil, fmt.Errorf("函数 %s 没有实现", key)
+		}
+		table[key] = f
+	}
+	return &funcTable{name: name, version: version, funcs: table, logger: logger}, nil
+}
+
+// Invoke 不带截止时间调用, 见 InvokeContext
+func (t *funcTable) Invoke(method string, args []interface{}, options Options) (interface{}, error) {
+	return t.InvokeContext(context.Background(), method, args, options)
+}
+
+// InvokeContext 方法不存在时返回 CodeNotFound, 参数或选项无效时返回 CodeInvalidArgument
+// 函数设置了 CallContext 时上下文传给函数, 由函数自行在取消时停止;
+// 否则上下文结束时立即返回, 函数仍在插件进程中运行到结束, 取消只对宿主一侧生效
+func (t *funcTable) InvokeContext(ctx context.Context, method string, args []interface{}, options Options) (interface{}, error) {
+	f, ok := t.funcs[method]
+	if !ok {
+		return nil, t.notFound(method)
+	}
+	if !f.HasArgs {
+		if len(args) > 0 {
+			return nil, NewError(CodeInvalidArgument, "方法 %s 不接受参数, 传入了 %d 个", method, len(args))
+		}
+		args = []interface{}{}
+	}
+	if !f.HasOptions && len(options) > 0 {
+		return nil, NewError(CodeInvalidArgument, "方法 %s 不接受选项", method)
+	}
+	options, err := CheckOptions(f.Options, options)
+	if err != nil {
+		return nil, err
+	}
+	if f.CallContext != nil {
+		return f.CallContext(ctx, args, options)
+	}
+	return AwaitContext(ctx, func() (interface{}, error) {
+		return f.Call(args, options)
+	})
+}
+
+func (t *funcTable) Help(method string) (string, error) {
+	f, ok := t.funcs[method]
+	if !ok {
+		return "", t.notFound(method)
+	}
+	return f.GetFuncHelp(), nil
+}
+
+func (t *funcTable) Version() string {
+	return t.version
+}
+
+func (t *funcTable) Name() string {
+	return t.name
+}
+
+func (t *funcTable) Exports() ([]FuncSpec, error) {
+	return ExportTable(t.funcs), nil
+}
+
+func (t *funcTable) notFound(method string) error {
+	err := NewError(CodeNotFound, "method %s not found", method)
+	t.logger.Error(err.Error())
+	return err
+}
@@ -0,0 +1,143 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+func TestFuncTableInvokeContext(t *testing.T) {
+	stopped := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	table, err := newFuncTable("test", "1.0.0", map[string]DynamicFunc{
+		// 接受上下文的函数在取消时提前返回
+		"Wait": Wrap(func(ctx context.Context, name string) (string, error) {
+			defer close(stopped)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}, DynamicFunc{}),
+		// 不接受上下文的函数只能运行到结束
+		"Block": Wrap(func() string {
+			<-release
+			return "done"
+		}, DynamicFunc{}),
+		"Echo": Wrap(func(ctx context.Context, s string) string {
+			return s
+		}, DynamicFunc{}),
+	}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params := table.funcs["Wait"].Params; len(params) != 1 || params[0].Type != "string" {
+		t.Errorf("Wait 的参数为 %+v, context.Context 不应计入参数", params)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := table.InvokeContext(ctx, "Wait", []interface{}{"x"}, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait 返回 %v, 期望 context.DeadlineExceeded", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Error("超时后 Wait 仍在运行")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := table.InvokeContext(ctx, "Block", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Block 返回 %v, 期望 context.DeadlineExceeded", err)
+	}
+
+	if result, err := table.Invoke("Echo", []interface{}{"hi"}, nil); err != nil || result != "hi" {
+		t.Errorf("Echo = %v, %v, 期望 hi", result, err)
+	}
+	if _, err := table.Invoke("Echo", []interface{}{1}, nil); CodeOf(err) != CodeInvalidArgument {
+		t.Errorf("Echo(1) 返回 %v, 期望 %s", err, CodeInvalidArgument)
+	}
+}
+
+func TestNewFuncTable(t *testing.T) {
+	echo := DynamicFunc{Call: func(args []interface{}, options Options) (interface{}, error) { return args[0], nil }}
+
+	tests := []struct {
+		name    string
+		plugin  string
+		version string
+		funcs   map[string]DynamicFunc
+		wantErr string
+	}{
+		{name: "缺少插件名", version: "1.0.0", funcs: map[string]DynamicFunc{"Echo": echo}, wantErr: "插件名不能为空"},
+		{name: "缺少版本号", plugin: "echo", funcs: map[string]DynamicFunc{"Echo": echo}, wantErr: "版本号不能为空"},
+		{name: "键与函数名不一致", plugin: "echo", version: "1.0.0", funcs: map[string]DynamicFunc{
+			"Echo": {Name: "Say", Call: echo.Call},
+		}, wantErr: "与函数名 Say 不一致"},
+		{name: "没有实现", plugin: "echo", version: "1.0.0", funcs: map[string]DynamicFunc{"Echo": {}}, wantErr: "没有实现"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newFuncTable(tt.plugin, tt.version, tt.funcs, hclog.NewNullLogger()); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newFuncTable 返回 %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFuncTable(t *testing.T) {
+	table, err := newFuncTable("echo", "1.2.0", map[string]DynamicFunc{
+		"Echo": {
+			Call: func(args []interface{}, options Options) (interface{}, error) {
+				return fmt.Sprint(args...), nil
+			},
+			Help:    "原样返回参数",
+			Params:  []Param{{Name: "s", Type: "string"}},
+			Returns: "string",
+			HasArgs: true,
+		},
+		"Now": {
+			Call:    func(args []interface{}, options Options) (interface{}, error) { return "now", nil },
+			Returns: "string",
+		},
+	}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if table.Version() != "1.2.0" || table.Name() != "echo" {
+		t.Errorf("Version = %s, Name = %s, 期望 1.2.0 和 echo", table.Version(), table.Name())
+	}
+	if result, err := table.Invoke("Echo", []interface{}{"hi"}, nil); err != nil || result != "hi" {
+		t.Errorf("Echo = %v, %v, 期望 hi", result, err)
+	}
+	if result, err := table.Invoke("Now", nil, nil); err != nil || result != "now" {
+		t.Errorf("Now = %v, %v, 期望 now", result, err)
+	}
+	if _, err := table.Invoke("Now", []interface{}{"x"}, nil); CodeOf(err) != CodeInvalidArgument || !strings.Contains(err.Error(), "不接受参数") {
+		t.Errorf("向不接受参数的 Now 传入参数返回 %v, 期望 %s", err, CodeInvalidArgument)
+	}
+	if _, err := table.Invoke("Missing", nil, nil); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("调用不存在的方法返回 %v", err)
+	}
+	if help, err := table.Help("Echo"); err != nil || help != "原样返回参数" {
+		t.Errorf("Help(Echo) = %q, %v", help, err)
+	}
+	if _, err := table.Help("Missing"); err == nil {
+		t.Error("Help(Missing) 应返回错误")
+	}
+
+	specs, err := table.Exports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[0].Name != "Echo" || specs[1].Name != "Now" || specs[0].Returns != "string" {
+		t.Errorf("Exports = %+v, 期望按名称排序的 Echo 和 Now", specs)
+	}
+}
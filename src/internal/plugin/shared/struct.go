@@ -1,91 +1,179 @@
 package dynamic_plugin_shared
 
 import (
+	"context"
 	"net/rpc"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/go-plugin"
 )
 
+// Param 描述函数的一个位置参数或关键字选项
+type Param struct {
+	Name        string
+	Type        string
+	Optional    bool
+	Default     string   // 默认值的文本形式, 按 Type 解析
+	Enum        []string // 允许的取值, 为空表示不限制
+	Description string
+}
+
 type DynamicFunc struct {
+	Name string
+	Call func(args []interface{}, options Options) (interface{}, error)
+	// CallContext 可选, 设置后插件以宿主的上下文调用它而不是 Call, 函数可以在超时或取消时提前返回
+	CallContext func(ctx context.Context, args []interface{}, options Options) (interface{}, error)
+	Help        string
+	Params      []Param // 位置参数, 按顺序排列
+	Options     []Param // 关键字选项, 调用前由 CheckOptions 检查
+	Returns     string  // 返回值类型
+	HasArgs     bool
+	HasOptions  bool
+	Idempotent  bool // 重复调用没有副作用, 插件崩溃重启后宿主可以重试
+}
+
+// FuncSpec 导出函数的描述, 由 Exports 调用返回给宿主
+type FuncSpec struct {
 	Name       string
-	Call       func(args, options []interface{}) (interface{}, error)
+	Params     []Param
+	Options    []Param
+	Returns    string
 	Help       string
 	HasArgs    bool
 	HasOptions bool
+	Idempotent bool
 }
 
-// func (f *DynamicFunc) SafeCall(args, options []interface{}) ([]interface{}, error) {
-// 	val := reflect.ValueOf(f.Call)
-// 	if val.Kind() != reflect.Func {
-// 		panic("Call 字段必须为函数类型")
-// 	}
-//
-// 	// 准备参数（需要实际参数类型严格匹配）
-// 	in := make([]reflect.Value, len(args))
-// 	for i, arg := range args {
-// 		in[i] = reflect.ValueOf(arg)
-// 	}
-//
-// 	// 调用函数并返回结果
-// 	results := val.Call(in)
-// 	out := make([]interface{}, len(results))
-// 	for i, v := range results {
-// 		out[i] = v.Interface()
-// 	}
-// 	return out
-// }
-
 func (f *DynamicFunc) GetFuncHelp() string {
 	return f.Help
 }
 
+func (f *DynamicFunc) Spec() FuncSpec {
+	return FuncSpec{
+		Name:       f.Name,
+		Params:     f.Params,
+		Options:    f.Options,
+		Returns:    f.Returns,
+		Help:       f.Help,
+		HasArgs:    f.HasArgs,
+		HasOptions: f.HasOptions,
+		Idempotent: f.Idempotent,
+	}
+}
+
+// ExportTable 将函数表转换为按名称排序的导出描述
+func ExportTable(funcs map[string]DynamicFunc) []FuncSpec {
+	specs := make([]FuncSpec, 0, len(funcs))
+	for _, f := range funcs {
+		specs = append(specs, f.Spec())
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// InvokeArgs Invoke调用在RPC上传递的参数, 参数和选项都以 Value 编码
+type InvokeArgs struct {
+	Method   string
+	Args     []Value
+	Options  map[string]Value
+	Deadline time.Time // 调用截止时间, 零值表示不限制
+}
+
 type DynamicPluginRPCClient struct {
 	client *rpc.Client
 }
 
-func (c *DynamicPluginRPCClient) Invoke(method string, args, options []interface{}) (interface{}, error) {
-	var resp interface{}
-	err := c.client.Call("Plugin.Invoke", struct {
-		Method  string
-		Args    []interface{}
-		Options []interface{}
-	}{method, args, options}, &resp)
-	return resp, err
+func (c *DynamicPluginRPCClient) Invoke(method string, args []interface{}, options Options) (interface{}, error) {
+	return c.InvokeContext(context.Background(), method, args, options)
+}
+
+// InvokeContext 上下文的截止时间随请求传给插件; 上下文结束时立即返回, 不再等待插件响应
+func (c *DynamicPluginRPCClient) InvokeContext(ctx context.Context, method string, args []interface{}, options Options) (interface{}, error) {
+	argValues, err := ToValues(args)
+	if err != nil {
+		return nil, NewError(CodeInvalidArgument, "参数无法编码: %v", err)
+	}
+	optionValues, err := options.toValues()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	var resp Value
+	call := c.client.Go("Plugin.Invoke", InvokeArgs{
+		Method:   method,
+		Args:     argValues,
+		Options:  optionValues,
+		Deadline: deadline,
+	}, &resp, nil)
+
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			return nil, DecodeRPCError(call.Error)
+		}
+		return resp.Interface(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 func (c *DynamicPluginRPCClient) Help(method string) (string, error) {
 	var resp string
 	err := c.client.Call("Plugin.Help", method, &resp)
-	return resp, err
+	return resp, DecodeRPCError(err)
 }
 
 func (c *DynamicPluginRPCClient) Version() string {
 	var version string
-	err := c.client.Call("Plugin.Version", struct{}{}, &version)
+	err := c.client.Call("Plugin.Version", new(interface{}), &version)
 	if err != nil {
 		return "unknown"
 	}
 	return version
 }
 
+// Name 返回插件报告的名称, 调用失败时返回空字符串
+func (c *DynamicPluginRPCClient) Name() string {
+	var name string
+	if err := c.client.Call("Plugin.Name", new(interface{}), &name); err != nil {
+		return ""
+	}
+	return name
+}
+
+func (c *DynamicPluginRPCClient) Exports() ([]FuncSpec, error) {
+	var resp []FuncSpec
+	err := c.client.Call("Plugin.Exports", new(interface{}), &resp)
+	return resp, DecodeRPCError(err)
+}
+
 type DynamicPluginRPCServer struct {
 	Impl DynamicPluginInterface
 }
 
-func (s *DynamicPluginRPCServer) Invoke(args struct {
-	Method  string
-	Args    []interface{}
-	Options []interface{}
-}, resp *interface{}) error {
-	result, err := s.Impl.Invoke(args.Method, args.Args, args.Options)
-	*resp = result
-	return err
+// Invoke 返回的错误经 EncodeRPCError 编码, 宿主端可以还原错误码
+func (s *DynamicPluginRPCServer) Invoke(args InvokeArgs, resp *Value) error {
+	ctx, cancel := DeadlineContext(args.Deadline)
+	defer cancel()
+
+	result, err := InvokeContext(ctx, s.Impl, args.Method, FromValues(args.Args), optionsFromValues(args.Options))
+	if err != nil {
+		return EncodeRPCError(err)
+	}
+	value, err := ToValue(result)
+	if err != nil {
+		return EncodeRPCError(NewError(CodeInternal, "返回值无法编码: %v", err))
+	}
+	*resp = value
+	return nil
 }
 
 func (s *DynamicPluginRPCServer) Help(method string, resp *string) error {
 	result, err := s.Impl.Help(method)
 	*resp = result
-	return err
+	return EncodeRPCError(err)
 }
 
 func (s *DynamicPluginRPCServer) Version(args interface{}, resp *string) error {
@@ -93,6 +181,17 @@ func (s *DynamicPluginRPCServer) Version(args interface{}, resp *string) error {
 	return nil
 }
 
+func (s *DynamicPluginRPCServer) Name(args interface{}, resp *string) error {
+	*resp = s.Impl.Name()
+	return nil
+}
+
+func (s *DynamicPluginRPCServer) Exports(args interface{}, resp *[]FuncSpec) error {
+	result, err := s.Impl.Exports()
+	*resp = result
+	return EncodeRPCError(err)
+}
+
 type DynamicPlugin struct {
 	Impl DynamicPluginInterface
 }
@@ -0,0 +1,56 @@
+package dynamic_plugin_shared
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// 宿主使用自定义证书时, 通过以下环境变量把插件端的证书和CA传给插件进程
+const (
+	EnvTLSCAFile   = "DYNAMIC_PLUGIN_TLS_CA_FILE"
+	EnvTLSCertFile = "DYNAMIC_PLUGIN_TLS_CERT_FILE"
+	EnvTLSKeyFile  = "DYNAMIC_PLUGIN_TLS_KEY_FILE"
+)
+
+// TLSProvider 用作 plugin.ServeConfig.TLSProvider
+// 宿主传入了证书时, 插件端使用该证书并要求宿主出示同一CA签发的客户端证书;
+// 未传入时返回 nil, 宿主启用 AutoMTLS 时由 go-plugin 自动协商
+func TLSProvider() (*tls.Config, error) {
+	caFile, certFile, keyFile := os.Getenv(EnvTLSCAFile), os.Getenv(EnvTLSCertFile), os.Getenv(EnvTLSKeyFile)
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if caFile == "" || certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS配置不完整: 需要同时设置 %s、%s 和 %s", EnvTLSCAFile, EnvTLSCertFile, EnvTLSKeyFile)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载插件证书失败: %v", err)
+	}
+	pool, err := LoadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// LoadCertPool 读取PEM格式的CA证书
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取CA证书失败: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA证书 %s 中没有有效的PEM证书", caFile)
+	}
+	return pool, nil
+}
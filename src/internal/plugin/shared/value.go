@@ -0,0 +1,230 @@
+package dynamic_plugin_shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ValueKind 标记 Value 中实际保存的类型
+type ValueKind uint8
+
+const (
+	KindNull ValueKind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindString
+	KindBytes
+	KindTimestamp
+	KindDuration
+	KindList
+	KindMap
+)
+
+var kindNames = map[ValueKind]string{
+	KindNull:      "null",
+	KindBool:      "bool",
+	KindInt:       "int64",
+	KindFloat:     "float64",
+	KindString:    "string",
+	KindBytes:     "bytes",
+	KindTimestamp: "timestamp",
+	KindDuration:  "duration",
+	KindList:      "list",
+	KindMap:       "map",
+}
+
+func (k ValueKind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("ValueKind(%d)", k)
+}
+
+// Value 跨进程传递的带类型标记的值
+// 所有字段都是gob可直接编码的具体类型, 避免 interface{} 在gob中需要注册类型的问题
+type Value struct {
+	Kind  ValueKind
+	Bool  bool
+	Int   int64 // KindInt, KindDuration(纳秒)
+	Float float64
+	Str   string
+	Bytes []byte
+	Time  time.Time
+	List  []Value
+	Map   map[string]Value
+}
+
+// ToValue 将Go值转换为 Value
+// 整数统一为int64, 浮点数统一为float64, 切片转为列表, 键为字符串的map和结构体转为map
+func ToValue(v interface{}) (Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return Value{Kind: KindNull}, nil
+	case Value:
+		return x, nil
+	case bool:
+		return Value{Kind: KindBool, Bool: x}, nil
+	case string:
+		return Value{Kind: KindString, Str: x}, nil
+	case []byte:
+		return Value{Kind: KindBytes, Bytes: x}, nil
+	case time.Time:
+		return Value{Kind: KindTimestamp, Time: x}, nil
+	case *time.Time:
+		if x == nil {
+			return Value{Kind: KindNull}, nil
+		}
+		return Value{Kind: KindTimestamp, Time: *x}, nil
+	case time.Duration:
+		return Value{Kind: KindDuration, Int: int64(x)}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Value{Kind: KindInt, Int: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return Value{}, fmt.Errorf("整数 %d 超出int64范围", rv.Uint())
+		}
+		return Value{Kind: KindInt, Int: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return Value{Kind: KindFloat, Float: rv.Float()}, nil
+	case reflect.String:
+		return Value{Kind: KindString, Str: rv.String()}, nil
+	case reflect.Bool:
+		return Value{Kind: KindBool, Bool: rv.Bool()}, nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return Value{Kind: KindNull}, nil
+		}
+		return ToValue(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return Value{Kind: KindNull}, nil
+		}
+		list := make([]Value, rv.Len())
+		for i := range list {
+			item, err := ToValue(rv.Index(i).Interface())
+			if err != nil {
+				return Value{}, fmt.Errorf("[%d]: %v", i, err)
+			}
+			list[i] = item
+		}
+		return Value{Kind: KindList, List: list}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return Value{}, fmt.Errorf("不支持键类型为 %s 的map", rv.Type().Key())
+		}
+		if rv.IsNil() {
+			return Value{Kind: KindNull}, nil
+		}
+		m := make(map[string]Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := ToValue(iter.Value().Interface())
+			if err != nil {
+				return Value{}, fmt.Errorf("[%q]: %v", iter.Key().String(), err)
+			}
+			m[iter.Key().String()] = item
+		}
+		return Value{Kind: KindMap, Map: m}, nil
+	case reflect.Struct:
+		// 自定义结构体按JSON标签转换为map
+		data, err := json.Marshal(v)
+		if err != nil {
+			return Value{}, fmt.Errorf("无法转换结构体 %T: %v", v, err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return Value{}, fmt.Errorf("无法转换结构体 %T: %v", v, err)
+		}
+		return ToValue(m)
+	default:
+		return Value{}, fmt.Errorf("不支持的类型 %T", v)
+	}
+}
+
+// ToValues 批量转换, 用于参数和选项
+func ToValues(vs []interface{}) ([]Value, error) {
+	values := make([]Value, len(vs))
+	for i, v := range vs {
+		value, err := ToValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个值: %v", i+1, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// FromValues 将 Value 列表还原为Go值
+func FromValues(values []Value) []interface{} {
+	vs := make([]interface{}, len(values))
+	for i, v := range values {
+		vs[i] = v.Interface()
+	}
+	return vs
+}
+
+// Interface 还原为Go值
+// 列表还原为 []interface{}, map还原为 map[string]interface{}
+func (v Value) Interface() interface{} {
+	switch v.Kind {
+	case KindBool:
+		return v.Bool
+	case KindInt:
+		return v.Int
+	case KindFloat:
+		return v.Float
+	case KindString:
+		return v.Str
+	case KindBytes:
+		return v.Bytes
+	case KindTimestamp:
+		return v.Time
+	case KindDuration:
+		return time.Duration(v.Int)
+	case KindList:
+		return FromValues(v.List)
+	case KindMap:
+		m := make(map[string]interface{}, len(v.Map))
+		for k, item := range v.Map {
+			m[k] = item.Interface()
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+// String 返回值的可读形式
+func (v Value) String() string {
+	switch v.Kind {
+	case KindNull:
+		return "null"
+	case KindTimestamp:
+		return v.Time.Format(time.RFC3339Nano)
+	case KindMap:
+		keys := make([]string, 0, len(v.Map))
+		for k := range v.Map {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		s := "map["
+		for i, k := range keys {
+			if i > 0 {
+				s += " "
+			}
+			s += k + ":" + v.Map[k].String()
+		}
+		return s + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,108 @@
+package dynamic_plugin_shared
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValueGobRoundTrip(t *testing.T) {
+	type pair struct {
+		Key   string `json:"key"`
+		Count int    `json:"count"`
+	}
+	east8 := time.FixedZone("", 8*60*60)
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 6, east8)
+	n := 7
+
+	tests := []struct {
+		name string
+		in   interface{}
+		kind ValueKind
+		want interface{}
+	}{
+		{name: "null", in: nil, kind: KindNull, want: nil},
+		{name: "nil指针", in: (*int)(nil), kind: KindNull, want: nil},
+		{name: "nil切片", in: []string(nil), kind: KindNull, want: nil},
+		{name: "bool", in: true, kind: KindBool, want: true},
+		{name: "int", in: -3, kind: KindInt, want: int64(-3)},
+		{name: "uint8", in: uint8(200), kind: KindInt, want: int64(200)},
+		{name: "指针解引用", in: &n, kind: KindInt, want: int64(7)},
+		{name: "float32", in: float32(1.5), kind: KindFloat, want: 1.5},
+		{name: "string", in: "你好", kind: KindString, want: "你好"},
+		{name: "bytes", in: []byte{0, 1, 255}, kind: KindBytes, want: []byte{0, 1, 255}},
+		{name: "带时区偏移的时间", in: stamp, kind: KindTimestamp, want: stamp},
+		{name: "时长", in: 90 * time.Second, kind: KindDuration, want: 90 * time.Second},
+		{name: "列表", in: []interface{}{1, "a", nil}, kind: KindList, want: []interface{}{int64(1), "a", nil}},
+		{name: "数组", in: [2]bool{true, false}, kind: KindList, want: []interface{}{true, false}},
+		{name: "嵌套列表和map", in: map[string]interface{}{
+			"list": [][]int{{1}, {2, 3}},
+			"map":  map[string]time.Duration{"d": time.Second},
+		}, kind: KindMap, want: map[string]interface{}{
+			"list": []interface{}{[]interface{}{int64(1)}, []interface{}{int64(2), int64(3)}},
+			"map":  map[string]interface{}{"d": time.Second},
+		}},
+		{name: "结构体按JSON标签转换", in: pair{Key: "k", Count: 2}, kind: KindMap,
+			want: map[string]interface{}{"key": "k", "count": 2.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := ToValue(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value.Kind != tt.kind {
+				t.Errorf("Kind 为 %s, 期望 %s", value.Kind, tt.kind)
+			}
+
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+				t.Fatalf("gob编码失败: %v", err)
+			}
+			var decoded Value
+			if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+				t.Fatalf("gob解码失败: %v", err)
+			}
+
+			got := decoded.Interface()
+			if want, ok := tt.want.(time.Time); ok {
+				tm, _ := got.(time.Time)
+				_, offset := tm.Zone()
+				if !tm.Equal(want) || offset != 8*60*60 {
+					t.Errorf("时间为 %v, 期望 %v 且保留时区偏移", got, want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("往返后为 %#v, 期望 %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToValueUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+	}{
+		{name: "通道", in: make(chan int)},
+		{name: "函数", in: func() {}},
+		{name: "复数", in: complex(1, 2)},
+		{name: "非字符串键的map", in: map[int]string{1: "a"}},
+		{name: "超出int64的整数", in: uint64(1 << 63)},
+		{name: "列表中的不支持类型", in: []interface{}{1, make(chan int)}},
+		{name: "map中的不支持类型", in: map[string]interface{}{"f": func() {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value, err := ToValue(tt.in); err == nil {
+				t.Errorf("ToValue(%T) = %v, 期望返回错误", tt.in, value)
+			}
+		})
+	}
+	if _, err := ToValues([]interface{}{"a", make(chan int)}); err == nil {
+		t.Error("ToValues 应返回第 2 个值的错误")
+	}
+}
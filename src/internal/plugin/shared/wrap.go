@@ -0,0 +1,360 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	contextType  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// WrapFunc 将普通Go函数包装为 DynamicFunc
+// fn 的返回值可以是 ()、(T)、(error) 或 (T, error); spec 提供帮助信息、参数名和说明等,
+// 参数类型、返回类型、HasArgs 和 Call 由函数签名生成。调用时检查参数个数,
+// 按形参类型转换参数, 省略的可选参数传入零值, 函数 panic 时返回错误。
+// fn 的最后一个参数为 Options 时接受关键字选项, 选项由 spec.Options 声明, 传入前已经过 CheckOptions 检查。
+// fn 的第一个参数为 context.Context 时同时生成 CallContext, 宿主的截止时间和取消通过它传给函数,
+// 该参数不计入ABI中的参数
+func WrapFunc(fn interface{}, spec DynamicFunc) (DynamicFunc, error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return DynamicFunc{}, fmt.Errorf("%T 不是函数", fn)
+	}
+	ft := fv.Type()
+	if ft.IsVariadic() {
+		return DynamicFunc{}, fmt.Errorf("不支持可变参数函数 %s", ft)
+	}
+	returns, err := returnType(ft)
+	if err != nil {
+		return DynamicFunc{}, err
+	}
+
+	// 位置参数为 ft.In(first) 到 ft.In(last-1)
+	first, last := 0, ft.NumIn()
+	hasContext := last > 0 && ft.In(0) == contextType
+	if hasContext {
+		first++
+	}
+	hasOptions := last > first && ft.In(last-1) == optionsType
+	if hasOptions {
+		last--
+	}
+	for i := first; i < last; i++ {
+		if ft.In(i) == contextType {
+			return DynamicFunc{}, fmt.Errorf("函数 %s 的 context.Context 只能是第一个参数", ft)
+		}
+	}
+	numIn := last - first
+	if len(spec.Options) > 0 && !hasOptions {
+		return DynamicFunc{}, fmt.Errorf("声明了选项, 但函数 %s 的最后一个参数不是 Options", ft)
+	}
+	for _, option := range spec.Options {
+		if option.Name == "" || option.Type == "" {
+			return DynamicFunc{}, fmt.Errorf("选项必须声明名称和类型")
+		}
+	}
+	if len(spec.Params) > numIn {
+		return DynamicFunc{}, fmt.Errorf("描述了 %d 个参数, 但函数 %s 只有 %d 个", len(spec.Params), ft, numIn)
+	}
+
+	params := make([]Param, numIn)
+	copy(params, spec.Params)
+	for i := range params {
+		params[i].Type = typeName(ft.In(first + i))
+	}
+
+	call := func(ctx context.Context, args []interface{}, options Options) (interface{}, error) {
+		// 宿主对没有默认值的可选参数不传值, 以零值补齐
+		for i := len(args); i < len(params) && params[i].Optional; i++ {
+			args = append(args, reflect.Zero(ft.In(first+i)).Interface())
+		}
+		if hasOptions {
+			if len(args) > len(params) {
+				return nil, NewError(CodeInvalidArgument, "需要 %d 个参数, 实际 %d 个", len(params), len(args))
+			}
+			args = append(args, options)
+		}
+		var in []reflect.Value
+		if hasContext {
+			in = append(in, reflect.ValueOf(&ctx).Elem())
+		}
+		return safeCall(fv, in, args)
+	}
+
+	spec.Params = params
+	spec.Returns = returns
+	spec.HasArgs = len(params) > 0
+	spec.HasOptions = hasOptions
+	spec.Call = func(args []interface{}, options Options) (interface{}, error) {
+		return call(context.Background(), args, options)
+	}
+	spec.CallContext = nil
+	if hasContext {
+		spec.CallContext = call
+	}
+	return spec, nil
+}
+
+// Wrap 与 WrapFunc 相同, 函数不受支持时 panic, 用于初始化函数表
+func Wrap(fn interface{}, spec DynamicFunc) DynamicFunc {
+	f, err := WrapFunc(fn, spec)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// SafeCall 用反射调用任意函数
+// 参数个数必须与函数一致, 每个参数按形参类型转换, 不满足时返回 CodeInvalidArgument;
+// 返回值规则与 WrapFunc 相同, 函数 panic 时返回 CodeInternal, 函数自身返回的错误原样返回
+func SafeCall(fn interface{}, args ...interface{}) (result interface{}, err error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("%T 不是函数", fn)
+	}
+	return safeCall(fv, nil, args)
+}
+
+// safeCall 与 SafeCall 相同, in 为不需要转换的前置参数(例如上下文), 不计入参数序号
+func safeCall(fv reflect.Value, in []reflect.Value, args []interface{}) (result interface{}, err error) {
+	ft := fv.Type()
+	if _, err := returnType(ft); err != nil {
+		return nil, err
+	}
+	if ft.IsVariadic() || len(in)+len(args) != ft.NumIn() {
+		return nil, NewError(CodeInvalidArgument, "需要 %d 个参数, 实际 %d 个", ft.NumIn()-len(in), len(args))
+	}
+
+	for i, arg := range args {
+		v, err := convertArg(arg, ft.In(len(in)))
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "第 %d 个参数: %v", i+1, err)
+		}
+		in = append(in, v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, NewError(CodeInternal, "函数执行出错: %v", r)
+		}
+	}()
+	out := fv.Call(in)
+
+	if n := len(out); n > 0 && ft.Out(n-1) == errorType {
+		if e := out[n-1]; !e.IsNil() {
+			return nil, e.Interface().(error)
+		}
+		out = out[:n-1]
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// returnType 检查函数的返回值并返回ABI中的返回类型
+func returnType(ft reflect.Type) (string, error) {
+	switch {
+	case ft.NumOut() == 0:
+		return "void", nil
+	case ft.NumOut() == 1 && ft.Out(0) == errorType:
+		return "void", nil
+	case ft.NumOut() == 1:
+		return typeName(ft.Out(0)), nil
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+		return typeName(ft.Out(0)), nil
+	default:
+		return "", fmt.Errorf("函数 %s 的返回值必须是 (T)、(error) 或 (T, error)", ft)
+	}
+}
+
+// typeName 返回类型在ABI中的名称, 与宿主 CoerceValue 识别的类型名一致
+func typeName(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "time.Time"
+	case t == durationType:
+		return "time.Duration"
+	case t.Kind() == reflect.Interface && t.NumMethod() == 0:
+		return "any"
+	case t.Name() != "":
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	default:
+		return t.String()
+	}
+}
+
+// convertArg 将RPC传来的值转换为形参类型
+// 整数在传输中统一为int64, 浮点数为float64, 列表和map的元素为 interface{}, 这里逐层还原
+func convertArg(arg interface{}, t reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("期望 %s, 实际为空值", typeName(t))
+	}
+
+	v := reflect.ValueOf(arg)
+	if v.Type().AssignableTo(t) {
+		v2 := reflect.New(t).Elem()
+		v2.Set(v)
+		return v2, nil
+	}
+
+	mismatch := fmt.Errorf("期望 %s, 实际 %T", typeName(t), arg)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			if s, ok := arg.(string); ok {
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("无效的时间长度 %q", s)
+				}
+				return reflect.ValueOf(d), nil
+			}
+		}
+		n, ok := toInt64(v)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.New(t).Elem()
+		if out.OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("整数 %d 超出 %s 的范围", n, typeName(t))
+		}
+		out.SetInt(n)
+		return out, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toInt64(v)
+		if !ok {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.New(t).Elem()
+		if n < 0 || out.OverflowUint(uint64(n)) {
+			return reflect.Value{}, fmt.Errorf("整数 %d 超出 %s 的范围", n, typeName(t))
+		}
+		out.SetUint(uint64(n))
+		return out, nil
+	case reflect.Float32, reflect.Float64:
+		out := reflect.New(t).Elem()
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			out.SetFloat(v.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out.SetFloat(float64(v.Int()))
+		default:
+			return reflect.Value{}, mismatch
+		}
+		return out, nil
+	case reflect.String, reflect.Bool:
+		if v.Kind() != t.Kind() {
+			return reflect.Value{}, mismatch
+		}
+		return v.Convert(t), nil
+	case reflect.Ptr:
+		elem, err := convertArg(arg, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Slice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := convertArg(v.Index(i).Interface(), t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%d]: %v", i, err)
+			}
+			out.Index(i).Set(item)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.Kind() != reflect.Map || t.Key().Kind() != reflect.String || v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, mismatch
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := convertArg(iter.Value().Interface(), t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%q]: %v", iter.Key().String(), err)
+			}
+			out.SetMapIndex(iter.Key().Convert(t.Key()), item)
+		}
+		return out, nil
+	case reflect.Struct:
+		if t == timeType {
+			s, ok := arg.(string)
+			if !ok {
+				return reflect.Value{}, mismatch
+			}
+			tm, err := ParseTime(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("无效的时间 %q, 需要RFC3339或2006-01-02格式", s)
+			}
+			return reflect.ValueOf(tm), nil
+		}
+		// 结构体在传输中按JSON标签转换为map, 这里按同样的规则还原
+		if v.Kind() != reflect.Map {
+			return reflect.Value{}, mismatch
+		}
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("无法转换为 %s: %v", typeName(t), err)
+		}
+		out := reflect.New(t)
+		if err := json.Unmarshal(data, out.Interface()); err != nil {
+			return reflect.Value{}, fmt.Errorf("无法转换为 %s: %v", typeName(t), err)
+		}
+		return out.Elem(), nil
+	}
+	return reflect.Value{}, mismatch
+}
+
+// toInt64 接受任意整数, 以及没有小数部分的浮点数
+func toInt64(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > 1<<63-1 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != float64(int64(f)) {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// ParseTime 解析RFC3339或纯日期(2006-01-02)格式的时间
+// 插件按形参转换时间参数和宿主按ABI转换命令行参数都使用它, 两端接受的格式保持一致
+func ParseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
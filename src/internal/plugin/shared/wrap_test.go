@@ -0,0 +1,170 @@
+package dynamic_plugin_shared
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestWrapFunc(t *testing.T) {
+	tests := []struct {
+		name        string
+		fn          interface{}
+		spec        DynamicFunc
+		wantParams  []string // 参数类型
+		wantReturns string
+		wantOptions bool
+		wantContext bool
+		wantErr     string // 为空表示包装成功
+	}{
+		{name: "无参数无返回值", fn: func() {}, wantReturns: "void"},
+		{name: "只返回错误", fn: func(s string) error { return nil }, wantParams: []string{"string"}, wantReturns: "void"},
+		{name: "值和错误", fn: func(a, b float64) (float64, error) { return 0, nil },
+			wantParams: []string{"float64", "float64"}, wantReturns: "float64"},
+		{name: "复合类型", fn: func(t time.Time, d time.Duration, p *point, m map[string][]int) string { return "" },
+			wantParams: []string{"time.Time", "time.Duration", "*dynamic_plugin_shared.point", "map[string][]int"}, wantReturns: "string"},
+		{name: "上下文不计入参数", fn: func(ctx context.Context, n int) int { return n },
+			wantParams: []string{"int"}, wantReturns: "int", wantContext: true},
+		{name: "关键字选项", fn: func(s string, options Options) string { return s },
+			spec:       DynamicFunc{Options: []Param{{Name: "upper", Type: "bool"}}},
+			wantParams: []string{"string"}, wantReturns: "string", wantOptions: true},
+		{name: "上下文和选项", fn: func(ctx context.Context, options Options) {},
+			wantReturns: "void", wantOptions: true, wantContext: true},
+		{name: "不是函数", fn: 42, wantErr: "不是函数"},
+		{name: "可变参数", fn: func(s ...string) {}, wantErr: "不支持可变参数"},
+		{name: "返回值过多", fn: func() (int, int) { return 0, 0 }, wantErr: "返回值必须是"},
+		{name: "第二个返回值不是错误", fn: func() (int, string) { return 0, "" }, wantErr: "返回值必须是"},
+		{name: "上下文不是第一个参数", fn: func(s string, ctx context.Context) {}, wantErr: "只能是第一个参数"},
+		{name: "声明了选项但不接受", fn: func(s string) {},
+			spec: DynamicFunc{Options: []Param{{Name: "upper", Type: "bool"}}}, wantErr: "不是 Options"},
+		{name: "选项缺少名称", fn: func(options Options) {},
+			spec: DynamicFunc{Options: []Param{{Type: "bool"}}}, wantErr: "必须声明名称和类型"},
+		{name: "描述的参数过多", fn: func(s string) {},
+			spec: DynamicFunc{Params: []Param{{Name: "a"}, {Name: "b"}}}, wantErr: "描述了 2 个参数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := WrapFunc(tt.fn, tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("WrapFunc 返回 %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var params []string
+			for _, param := range f.Params {
+				params = append(params, param.Type)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("参数类型为 %v, 期望 %v", params, tt.wantParams)
+			}
+			if f.Returns != tt.wantReturns {
+				t.Errorf("返回类型为 %s, 期望 %s", f.Returns, tt.wantReturns)
+			}
+			if f.HasArgs != (len(tt.wantParams) > 0) || f.HasOptions != tt.wantOptions {
+				t.Errorf("HasArgs=%v HasOptions=%v, 期望 %v %v", f.HasArgs, f.HasOptions, len(tt.wantParams) > 0, tt.wantOptions)
+			}
+			if f.Call == nil || (f.CallContext != nil) != tt.wantContext {
+				t.Errorf("Call=%v CallContext=%v, 期望 CallContext: %v", f.Call != nil, f.CallContext != nil, tt.wantContext)
+			}
+		})
+	}
+}
+
+func TestWrapCall(t *testing.T) {
+	errFailed := errors.New("failed")
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fn       interface{}
+		spec     DynamicFunc
+		args     []interface{}
+		options  Options
+		want     interface{}
+		wantCode ErrorCode // 为空表示调用成功
+	}{
+		{name: "整数", fn: func(a int, b int32) int64 { return int64(a) + int64(b) },
+			args: []interface{}{int64(1), int64(2)}, want: int64(3)},
+		{name: "没有小数的浮点数转为整数", fn: func(n uint8) uint8 { return n }, args: []interface{}{3.0}, want: uint8(3)},
+		{name: "整数转为浮点数", fn: func(f float64) float64 { return f / 2 }, args: []interface{}{int64(3)}, want: 1.5},
+		{name: "时间字符串", fn: func(t time.Time) time.Time { return t }, args: []interface{}{"2024-01-02"}, want: date},
+		{name: "时长字符串", fn: func(d time.Duration) time.Duration { return d }, args: []interface{}{"2s"}, want: 2 * time.Second},
+		{name: "切片", fn: func(s []string) int { return len(s) }, args: []interface{}{[]interface{}{"a", "b"}}, want: 2},
+		{name: "map", fn: func(m map[string]int) int { return m["a"] },
+			args: []interface{}{map[string]interface{}{"a": int64(5)}}, want: 5},
+		{name: "结构体", fn: func(p point) int { return p.X + p.Y },
+			args: []interface{}{map[string]interface{}{"x": int64(1), "y": int64(2)}}, want: 3},
+		{name: "结构体指针", fn: func(p *point) int { return p.Y },
+			args: []interface{}{map[string]interface{}{"y": 4.0}}, want: 4},
+		{name: "可选参数补零值", fn: func(s string, n int) int { return len(s) + n },
+			spec: DynamicFunc{Params: []Param{{Name: "s"}, {Name: "n", Optional: true}}},
+			args: []interface{}{"ab"}, want: 2},
+		{name: "选项", fn: func(s string, options Options) string { return s + options["suffix"].(string) },
+			spec: DynamicFunc{Options: []Param{{Name: "suffix", Type: "string"}}},
+			args: []interface{}{"a"}, options: Options{"suffix": "b"}, want: "ab"},
+		{name: "类型不符", fn: func(n int) int { return n }, args: []interface{}{"1"}, wantCode: CodeInvalidArgument},
+		{name: "整数溢出", fn: func(n int8) int8 { return n }, args: []interface{}{int64(300)}, wantCode: CodeInvalidArgument},
+		{name: "无效的时间", fn: func(t time.Time) {}, args: []interface{}{"昨天"}, wantCode: CodeInvalidArgument},
+		{name: "结构体不是map", fn: func(p point) {}, args: []interface{}{"{}"}, wantCode: CodeInvalidArgument},
+		{name: "参数过少", fn: func(a, b int) {}, args: []interface{}{int64(1)}, wantCode: CodeInvalidArgument},
+		{name: "参数过多", fn: func(a int) {}, args: []interface{}{int64(1), int64(2)}, wantCode: CodeInvalidArgument},
+		{name: "函数返回错误", fn: func() error { return WrapError(CodeNotFound, errFailed) }, wantCode: CodeNotFound},
+		{name: "panic", fn: func() int { panic("boom") }, wantCode: CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Wrap(tt.fn, tt.spec)
+			got, err := f.Call(tt.args, tt.options)
+			if tt.wantCode != "" {
+				if CodeOf(err) != tt.wantCode {
+					t.Fatalf("调用返回 %v (%s), 期望 %s", err, CodeOf(err), tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("调用返回 %#v, 期望 %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Time
+		wantErr bool
+	}{
+		{raw: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{raw: "2024-01-02T03:04:05Z", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{raw: "2024-01-02T03:04:05+08:00", want: time.Date(2024, 1, 1, 19, 4, 5, 0, time.UTC)},
+		{raw: "2024/01/02", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseTime(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime(%q) 返回 %v", tt.raw, err)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, 期望 %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
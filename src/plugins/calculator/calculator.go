@@ -1,67 +1,61 @@
 package main
 
 import (
-	"errors"
-	"go-plugin-demo/src/plugins/calculator/shared"
-	"os"
-
-	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/go-plugin"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
 )
 
-type CalculatorImpl struct {
-	logger hclog.Logger
+// operands 两个操作数的方法使用的参数描述
+var operands = []dynamic_plugin_shared.Param{
+	{Name: "a", Description: "Left operand"},
+	{Name: "b", Description: "Right operand"},
 }
 
-func (c *CalculatorImpl) Add(a, b float64) (float64, error) {
-	res := a + b
-	c.logger.Info("Add called", "a", a, "b", b, "result", res)
-	return res, nil
+var ExportFuncMap = map[string]dynamic_plugin_shared.DynamicFunc{
+	"Add": dynamic_plugin_shared.Wrap(Add, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Adds two numbers.",
+		Params:     operands,
+		Idempotent: true,
+	}),
+	"Subtract": dynamic_plugin_shared.Wrap(Subtract, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Subtracts b from a.",
+		Params:     operands,
+		Idempotent: true,
+	}),
+	"Multiply": dynamic_plugin_shared.Wrap(Multiply, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Multiplies two numbers.",
+		Params:     operands,
+		Idempotent: true,
+	}),
+	"Divide": dynamic_plugin_shared.Wrap(Divide, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Divides a by b, b must not be zero.",
+		Params:     operands,
+		Idempotent: true,
+	}),
 }
 
-func (c *CalculatorImpl) Subtract(a, b float64) (float64, error) {
-	res := a - b
-	c.logger.Info("Subtract called", "a", a, "b", b, "result", res)
-	return res, nil
+// Add 加法
+func Add(a, b float64) float64 {
+	return a + b
 }
 
-func (c *CalculatorImpl) Multiply(a, b float64) (float64, error) {
-	res := a * b
-	c.logger.Info("Multiply called", "a", a, "b", b, "result", res)
-	return res, nil
+// Subtract 减法
+func Subtract(a, b float64) float64 {
+	return a - b
 }
 
-func (c *CalculatorImpl) Divide(a, b float64) (float64, error) {
-	if b == 0 {
-		return 0, errors.New("division by zero")
-	}
-	res := a / b
-	c.logger.Info("Divide called", "a", a, "b", b, "result", res)
-	return res, nil
+// Multiply 乘法
+func Multiply(a, b float64) float64 {
+	return a * b
 }
 
-var handshake = plugin.HandshakeConfig{
-	ProtocolVersion:  1,
-	MagicCookieKey:   "DYNAMIC_PLUGIN_CALCULATOR",
-	MagicCookieValue: "calculator",
+// Divide 除法, 除数为0时返回 CodeInvalidArgument
+func Divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, dynamic_plugin_shared.NewError(dynamic_plugin_shared.CodeInvalidArgument, "division by zero")
+	}
+	return a / b, nil
 }
 
 func main() {
-	logger := hclog.New(&hclog.LoggerOptions{
-		Level:      hclog.Trace,
-		Output:     os.Stderr,
-		JSONFormat: true,
-	})
-
-	calc := &CalculatorImpl{logger: logger}
-
-	var pluginMap = map[string]plugin.Plugin{
-		"calculator": &shared.CalculatorPlugin{Impl: calc},
-	}
-
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins:         pluginMap,
-	})
-
+	dynamic_plugin_shared.Serve("calculator", "1.0.0", ExportFuncMap)
 }
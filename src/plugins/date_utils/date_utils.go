@@ -1,172 +1,84 @@
 package main
 
 import (
-	"errors"
-	"fmt"
 	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
-	"os"
 	"time"
 
-	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/go-plugin"
+	// 插件可能运行在没有时区数据库的环境中
+	_ "time/tzdata"
 )
 
 var ExportFuncMap = map[string]dynamic_plugin_shared.DynamicFunc{
-	"AddDays": {
-		Name:       "AddDays",
-		Call:       AddDays,
-		Help:       "Adds days to a given date.",
-		HasArgs:    true,
-		HasOptions: false,
-	},
-	"Format": {
-		Name:       "Format",
-		Call:       Format,
-		Help:       "Formats a date to a specified layout.",
-		HasArgs:    true,
-		HasOptions: false,
-	},
-	"Parse": {
-		Name:       "Parse",
-		Call:       Parse,
-		Help:       "Parses a date string into a time.Time object.",
-		HasArgs:    true,
-		HasOptions: false,
-	},
-	"Between": {
-		Name:       "Between",
-		Call:       Between,
-		Help:       "Calculates the number of days between two dates.",
-		HasArgs:    true,
-		HasOptions: false,
-	},
-}
-
-type DataUtilsImpl struct {
-	logger hclog.Logger
-}
-
-func (ds *DataUtilsImpl) Invoke(method string, args, options []interface{}) (interface{}, error) {
-	// 判断method是否在ExportFuncMap中, 如果存在返回nil和error，方法不存在
-	if f, ok := ExportFuncMap[method]; ok {
-		if f.HasArgs && f.HasOptions {
-			return f.Call(args, options)
-		} else if f.HasArgs {
-			return f.Call(args, []interface{}{})
-		} else {
-			return f.Call([]interface{}{}, []interface{}{})
-		}
-	} else {
-		msg := fmt.Sprintf("method %s not found", method)
-		ds.logger.Error(msg)
-		return nil, errors.New(msg)
-	}
-}
-
-func (ds *DataUtilsImpl) Help(method string) (string, error) {
-	if f, ok := ExportFuncMap[method]; ok {
-		return f.GetFuncHelp(), nil
-	} else {
-		msg := fmt.Sprintf("method %s not found", method)
-		ds.logger.Error(msg)
-		return "", errors.New(msg)
-	}
-}
-
-func (ds *DataUtilsImpl) Version() string {
-	return "1.0.0"
+	"AddDays": dynamic_plugin_shared.Wrap(AddDays, dynamic_plugin_shared.DynamicFunc{
+		Help: "Adds days to a given date.",
+		Params: []dynamic_plugin_shared.Param{
+			{Name: "date", Description: "Date to start from, RFC3339 or 2006-01-02"},
+			{Name: "days", Description: "Number of days to add, negative to subtract"},
+		},
+		Idempotent: true,
+	}),
+	"Format": dynamic_plugin_shared.Wrap(Format, dynamic_plugin_shared.DynamicFunc{
+		Help: "Formats a date to a specified layout.",
+		Params: []dynamic_plugin_shared.Param{
+			{Name: "date", Description: "Date to format, RFC3339 or 2006-01-02"},
+			{Name: "layout", Description: "Go time layout, e.g. 2006-01-02 15:04:05"},
+		},
+		Options: []dynamic_plugin_shared.Param{
+			{Name: "timezone", Type: "string", Description: "IANA time zone to convert the date to before formatting, e.g. Asia/Shanghai"},
+		},
+		Idempotent: true,
+	}),
+	"Parse": dynamic_plugin_shared.Wrap(Parse, dynamic_plugin_shared.DynamicFunc{
+		Help: "Parses a date string into a time.Time object.",
+		Params: []dynamic_plugin_shared.Param{
+			{Name: "dateStr", Description: "Date string to parse"},
+			{Name: "layout", Description: "Go time layout of dateStr, e.g. 2006-01-02"},
+		},
+		Idempotent: true,
+	}),
+	"Between": dynamic_plugin_shared.Wrap(Between, dynamic_plugin_shared.DynamicFunc{
+		Help: "Calculates the number of days between two dates.",
+		Params: []dynamic_plugin_shared.Param{
+			{Name: "start", Description: "Start date, RFC3339 or 2006-01-02"},
+			{Name: "end", Description: "End date, RFC3339 or 2006-01-02"},
+		},
+		Idempotent: true,
+	}),
 }
 
 // AddDays 日期加减
-// func AddDays(date time.Time, days int) time.Time {
-func AddDays(args, options []interface{}) (interface{}, error) {
-	//return date.AddDate(0, 0, days)
-	if len(args) != 2 {
-		return nil, errors.New("AddDays requires exactly 2 arguments: date and days")
-	}
-	//fmt.Printf("%v", args...)
-	date, ok1 := time.Parse(time.RFC3339, args[0].(string))
-	if ok1 != nil {
-		return nil, ok1
-	}
-	days, ok2 := args[1].(int)
-	if !ok2 {
-		return nil, errors.New("AddDays requires arguments of type time.Time and int")
-	}
-	return date.AddDate(0, 0, days).Format(time.RFC3339), nil
+func AddDays(date time.Time, days int) time.Time {
+	return date.AddDate(0, 0, days)
 }
 
-// Format 日期格式化
-// func Format(date time.Time, layout string) string {
-func Format(args, options []interface{}) (interface{}, error) {
-	//return date.Format(layout)
-	if len(args) != 2 {
-		return nil, errors.New("Format requires exactly 2 arguments: date and layout")
-	}
-	date, ok1 := time.Parse(time.RFC3339, args[0].(string))
-	if ok1 != nil {
-		return nil, ok1
-	}
-	layout, ok2 := args[1].(string)
-	if !ok2 {
-		return nil, errors.New("Format requires arguments of type time.Time and string")
+// Format 日期格式化, 设置了 timezone 选项时先转换到该时区
+func Format(date time.Time, layout string, opts dynamic_plugin_shared.Options) (string, error) {
+	if tz, _ := opts["timezone"].(string); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return "", dynamic_plugin_shared.NewError(dynamic_plugin_shared.CodeInvalidArgument, "unknown timezone %q", tz).
+				WithDetail("timezone", tz)
+		}
+		date = date.In(loc)
 	}
 	return date.Format(layout), nil
 }
 
 // Parse 日期解析
-// func Parse(dateStr, layout string) (time.Time, error) {
-func Parse(args, options []interface{}) (interface{}, error) {
-	//return time.Parse(layout, dateStr)
-	if len(args) != 2 {
-		return nil, errors.New("Parse requires exactly 2 arguments: dateStr and layout")
-	}
-	dateStr, ok1 := args[0].(string)
-	layout, ok2 := args[1].(string)
-	if !ok1 || !ok2 {
-		return nil, errors.New("Parse requires arguments of type string and string")
-	}
-	res, err := time.Parse(layout, dateStr)
+func Parse(dateStr, layout string) (time.Time, error) {
+	t, err := time.Parse(layout, dateStr)
 	if err != nil {
-		return nil, err
+		return time.Time{}, dynamic_plugin_shared.WrapError(dynamic_plugin_shared.CodeInvalidArgument, err)
 	}
-	return res.Format(time.RFC3339), nil
+	return t, nil
 }
 
 // Between 计算日期差值
-// func Between(start, end time.Time) int {
-func Between(args, options []interface{}) (interface{}, error) {
-	//duration := end.Sub(start)
-	//return int(duration.Hours() / 24)
-	if len(args) != 2 {
-		return nil, errors.New("Between requires exactly 2 arguments: start and end")
-	}
-	start, ok1 := time.Parse(time.RFC3339, args[0].(string))
-	end, ok2 := time.Parse(time.RFC3339, args[1].(string))
-	if ok1 != nil || ok2 != nil {
-		return nil, errors.New("Between requires arguments of type time.Time and time.Time")
-	}
+func Between(start, end time.Time) int {
 	duration := end.Sub(start)
-	return int(duration.Hours() / 24), nil
+	return int(duration.Hours() / 24)
 }
 
 func main() {
-	handshake := dynamic_plugin_shared.GenHandShakeConfig("calculator")
-
-	logger := hclog.New(&hclog.LoggerOptions{
-		Level:      hclog.Trace,
-		Output:     os.Stderr,
-		JSONFormat: true,
-	})
-
-	ds := &DataUtilsImpl{logger: logger}
-
-	var pluginMap = map[string]plugin.Plugin{
-		"date_utils": &dynamic_plugin_shared.DynamicPlugin{Impl: ds},
-	}
-
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins:         pluginMap,
-	})
+	dynamic_plugin_shared.Serve("date_utils", "1.0.0", ExportFuncMap)
 }
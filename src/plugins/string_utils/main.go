@@ -1,65 +1,53 @@
 package main
 
 import (
-	"net/rpc"
-
-	"github.com/hashicorp/go-plugin"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
 )
 
-// StringUtilsImplementation 实现字符串工具接口
-type StringUtilsImplementation struct{}
-
-func (s *StringUtilsImplementation) Reverse(str string) (string, error) {
-	return Reverse(str), nil
-}
-
-func (s *StringUtilsImplementation) ToUpper(str string) (string, error) {
-	return ToUpper(str), nil
-}
-
-func (s *StringUtilsImplementation) ToLower(str string) (string, error) {
-	return ToLower(str), nil
-}
-
-func (s *StringUtilsImplementation) ToTitle(str string) (string, error) {
-	return ToTitle(str), nil
-}
-
-func (s *StringUtilsImplementation) ToCamel(str string) (string, error) {
-	return ToCamel(str), nil
-}
-
-func (s *StringUtilsImplementation) ToSnake(str string) (string, error) {
-	return ToSnake(str), nil
-}
-
-// StringUtilsPlugin 实现plugin.Plugin接口
-type StringUtilsPlugin struct {
-	Impl *StringUtilsImplementation
-}
-
-func (p *StringUtilsPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
-	return p.Impl, nil
-}
-
-func (p *StringUtilsPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
-	return p.Impl, nil
+// stringParam 只有一个字符串参数的方法使用的参数描述
+var stringParam = []dynamic_plugin_shared.Param{{Name: "str", Description: "String to convert"}}
+
+var ExportFuncMap = map[string]dynamic_plugin_shared.DynamicFunc{
+	"Reverse": dynamic_plugin_shared.Wrap(Reverse, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Reverses a string by runes.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"ToUpper": dynamic_plugin_shared.Wrap(ToUpper, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Converts a string to upper case.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"ToLower": dynamic_plugin_shared.Wrap(ToLower, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Converts a string to lower case.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"ToTitle": dynamic_plugin_shared.Wrap(ToTitle, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Converts a string to title case.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"ToCamel": dynamic_plugin_shared.Wrap(ToCamel, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Converts space separated words to camelCase.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"ToSnake": dynamic_plugin_shared.Wrap(ToSnake, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Converts a camelCase string to snake_case.",
+		Params:     stringParam,
+		Idempotent: true,
+	}),
+	"Join": dynamic_plugin_shared.Wrap(Join, dynamic_plugin_shared.DynamicFunc{
+		Help: "Joins strings with a separator.",
+		Params: []dynamic_plugin_shared.Param{
+			{Name: "parts", Description: "Strings to join, a JSON array such as [\"a\",\"b\"]"},
+			{Name: "sep", Description: "Separator placed between the strings"},
+		},
+		Idempotent: true,
+	}),
 }
 
 func main() {
-	handshake := plugin.HandshakeConfig{
-		ProtocolVersion:  1,
-		MagicCookieKey:   "DYNAMIC_PLUGIN_STRING_UTILS",
-		MagicCookieValue: "string_utils",
-	}
-
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"string_utils": &StringUtilsPlugin{
-				Impl: &StringUtilsImplementation{},
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-	})
+	dynamic_plugin_shared.Serve("string_utils", "1.0.0", ExportFuncMap)
 }
@@ -29,6 +29,11 @@ func ToTitle(s string) string {
 	return strings.ToTitle(s)
 }
 
+// Join 用分隔符连接字符串
+func Join(parts []string, sep string) string {
+	return strings.Join(parts, sep)
+}
+
 // ToCamel 转换为驼峰命名
 func ToCamel(s string) string {
 	words := strings.Fields(s)
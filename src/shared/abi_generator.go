@@ -2,8 +2,14 @@ package shared
 
 import (
 	"fmt"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+	"go/build"
+	"go/types"
+	"path/filepath"
 	"reflect"
 	"sync"
+
+	"golang.org/x/tools/go/packages"
 )
 
 // ABIGenerator 自动生成插件ABI描述
@@ -72,13 +78,13 @@ func (g *ABIGenerator) GenerateFromInstance(pluginName, version string, plugin i
 		}
 
 		methodSpec := MethodSpec{
-			Params:  make([]string, method.Type.NumIn()-1), // 减去接收者参数
+			Params:  make([]ParamSpec, method.Type.NumIn()-1), // 减去接收者参数
 			Returns: g.getTypeName(method.Type.Out(0)),
 		}
 
 		// 处理参数类型
 		for j := 1; j < method.Type.NumIn(); j++ {
-			methodSpec.Params[j-1] = g.getTypeName(method.Type.In(j))
+			methodSpec.Params[j-1] = ParamSpec{Type: g.getTypeName(method.Type.In(j))}
 		}
 
 		// 处理错误返回值
@@ -92,6 +98,72 @@ func (g *ABIGenerator) GenerateFromInstance(pluginName, version string, plugin i
 	return abi, nil
 }
 
+// GenerateFromExports 从插件导出的 DynamicFunc 函数表生成ABI描述
+func (g *ABIGenerator) GenerateFromExports(pluginName, version string, exports []dynamic_plugin_shared.FuncSpec) (*PluginABI, error) {
+	abi := &PluginABI{
+		Name:    pluginName,
+		Version: version,
+		Methods: make(map[string]MethodSpec),
+	}
+
+	for _, f := range exports {
+		if f.Name == "" {
+			return nil, fmt.Errorf("导出函数缺少名称")
+		}
+		if _, ok := abi.Methods[f.Name]; ok {
+			return nil, fmt.Errorf("导出函数 %s 重复", f.Name)
+		}
+
+		methodSpec := MethodSpec{
+			Params:     g.convertParams(f.Params),
+			Options:    g.convertParams(f.Options),
+			Returns:    "interface{}",
+			Help:       f.Help,
+			Idempotent: f.Idempotent,
+		}
+
+		// DynamicFunc 总是可能返回错误
+		if f.Returns != "" {
+			methodSpec.Returns = g.mapTypeName(f.Returns)
+		}
+		methodSpec.Returns += ",error"
+
+		abi.Methods[f.Name] = methodSpec
+	}
+
+	return abi, nil
+}
+
+// convertParams 将插件端的参数描述转换为 ParamSpec, 类型名按映射表转换
+func (g *ABIGenerator) convertParams(params []dynamic_plugin_shared.Param) []ParamSpec {
+	if len(params) == 0 {
+		return nil
+	}
+	specs := make([]ParamSpec, len(params))
+	for i, param := range params {
+		specs[i] = ParamSpec{
+			Name:        param.Name,
+			Type:        g.mapTypeName(param.Type),
+			Optional:    param.Optional,
+			Default:     param.Default,
+			Enum:        param.Enum,
+			Description: param.Description,
+		}
+	}
+	return specs
+}
+
+// mapTypeName 按基础类型映射和自定义类型映射转换类型名
+func (g *ABIGenerator) mapTypeName(name string) string {
+	if mapped, ok := basicTypeMap[name]; ok {
+		return mapped
+	}
+	if mapped, ok := g.GetTypeMapping(name); ok {
+		return mapped
+	}
+	return name
+}
+
 // getTypeName 获取类型的可读名称
 func (g *ABIGenerator) getTypeName(t reflect.Type) string {
 	// 检查基础类型映射
@@ -121,8 +193,194 @@ func (g *ABIGenerator) getTypeName(t reflect.Type) string {
 	}
 }
 
-// GenerateFromPackage 从包路径生成ABI描述(需要插件实现DynamicPlugin接口)
+// abiInterface 静态分析时用于识别插件实现类型的接口
+type abiInterface struct {
+	pkgPath string
+	name    string
+	// protocol 为true时接口自身的方法属于插件协议, 不计入ABI
+	protocol bool
+}
+
+// abiInterfaces 插件实现类型需要满足的接口, 按顺序匹配
+var abiInterfaces = []abiInterface{
+	{pkgPath: reflect.TypeOf(PluginABI{}).PkgPath(), name: "DynamicPlugin", protocol: true},
+}
+
+// GenerateFromPackage 通过静态分析插件源码生成ABI描述, 不需要编译和运行插件
+// 插件调用 dynamic_plugin_shared.Serve 时按传给它的函数表生成, 插件名和版本以源码为准,
+// 与 pluginName 不一致时返回错误; 否则查找实现了 DynamicPlugin 接口的类型, 按其方法集生成
+// pkgPath 可以是导入路径, 也可以是 ./src/plugins/calculator 这样的相对目录
 func (g *ABIGenerator) GenerateFromPackage(pluginName, version, pkgPath string) (*PluginABI, error) {
-	// TODO: 实现从包路径加载并分析
-	return nil, fmt.Errorf("未实现")
+	pkg, err := loadSourcePackage(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	serve, err := pkg.serveCall()
+	if err != nil {
+		return nil, err
+	}
+	if serve != nil {
+		return g.generateFromServe(pluginName, pkg, serve)
+	}
+
+	for _, iface := range abiInterfaces {
+		obj := pkg.lookup(iface.pkgPath, iface.name)
+		if obj == nil {
+			continue
+		}
+		ifaceType, ok := obj.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+
+		implType := findImplementation(pkg.types, ifaceType)
+		if implType == nil {
+			continue
+		}
+		return g.generateFromType(pluginName, version, implType, ifaceType, iface.protocol), nil
+	}
+
+	return nil, fmt.Errorf("包 %s 中没有调用 dynamic_plugin_shared.Serve, 也没有实现 DynamicPlugin 接口的类型", pkgPath)
+}
+
+// selectPackage 在加载结果中找到 pkgPath 指定的插件包
+// packages.Load 不保证返回顺序与模式一致, pkgPath 为目录时按包所在目录匹配, 否则按导入路径匹配
+func selectPackage(pkgs []*packages.Package, pkgPath string) (*packages.Package, error) {
+	dir := ""
+	if build.IsLocalImport(pkgPath) || filepath.IsAbs(pkgPath) {
+		abs, err := filepath.Abs(pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("解析包目录 %s 失败: %v", pkgPath, err)
+		}
+		dir = abs
+	}
+
+	var matched []*packages.Package
+	for _, pkg := range pkgs {
+		if dir == "" && pkg.PkgPath == pkgPath || dir != "" && packageDir(pkg) == dir {
+			matched = append(matched, pkg)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("没有找到包 %s", pkgPath)
+	case 1:
+		return matched[0], nil
+	default:
+		return nil, fmt.Errorf("%s 匹配了 %d 个包, 需要指定单个插件包", pkgPath, len(matched))
+	}
+}
+
+// packageDir 返回包源文件所在的目录
+func packageDir(pkg *packages.Package) string {
+	if len(pkg.GoFiles) == 0 {
+		return ""
+	}
+	return filepath.Dir(pkg.GoFiles[0])
+}
+
+// findImplementation 查找包中实现了指定接口的具名类型, 返回其指针类型
+func findImplementation(pkg *types.Package, iface *types.Interface) types.Type {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		if types.IsInterface(typeName.Type()) {
+			continue
+		}
+		ptr := types.NewPointer(typeName.Type())
+		if types.Implements(ptr, iface) {
+			return ptr
+		}
+	}
+	return nil
+}
+
+// generateFromType 根据类型的方法集生成ABI描述, 规则与 GenerateFromInstance 一致
+func (g *ABIGenerator) generateFromType(pluginName, version string, t types.Type, iface *types.Interface, protocol bool) *PluginABI {
+	abi := &PluginABI{
+		Name:    pluginName,
+		Version: version,
+		Methods: make(map[string]MethodSpec),
+	}
+
+	methodSet := types.NewMethodSet(t)
+	for i := 0; i < methodSet.Len(); i++ {
+		method := methodSet.At(i).Obj().(*types.Func)
+		if !method.Exported() {
+			continue
+		}
+		if protocol {
+			if obj, _, _ := types.LookupFieldOrMethod(iface, false, nil, method.Name()); obj != nil {
+				continue
+			}
+		}
+
+		sig := method.Type().(*types.Signature)
+		methodSpec := MethodSpec{
+			Params:  make([]ParamSpec, sig.Params().Len()),
+			Returns: "void",
+		}
+
+		// 处理参数名称和类型
+		for j := 0; j < sig.Params().Len(); j++ {
+			param := sig.Params().At(j)
+			methodSpec.Params[j] = ParamSpec{Type: g.getGoTypeName(param.Type())}
+			if param.Name() != "_" {
+				methodSpec.Params[j].Name = param.Name()
+			}
+		}
+
+		// 处理返回值及错误返回值
+		if sig.Results().Len() > 0 {
+			methodSpec.Returns = g.getGoTypeName(sig.Results().At(0).Type())
+		}
+		if sig.Results().Len() > 1 {
+			methodSpec.Returns += ",error"
+		}
+
+		abi.Methods[method.Name()] = methodSpec
+	}
+
+	return abi
+}
+
+// getGoTypeName 获取静态类型的可读名称, 与 getTypeName 的命名规则保持一致
+func (g *ABIGenerator) getGoTypeName(t types.Type) string {
+	t = types.Unalias(t)
+
+	var name string
+	switch t := t.(type) {
+	case *types.Basic:
+		// byte、rune 等别名统一为底层类型名
+		name = types.Typ[t.Kind()].Name()
+	case *types.Named:
+		name = t.Obj().Name()
+	}
+
+	// 检查基础类型映射
+	if mapped, ok := basicTypeMap[name]; ok {
+		return mapped
+	}
+
+	// 检查自定义类型映射
+	if mapped, ok := g.GetTypeMapping(name); ok {
+		return mapped
+	}
+
+	switch u := t.(type) {
+	case *types.Pointer:
+		return "*" + g.getGoTypeName(u.Elem())
+	case *types.Slice:
+		return "[]" + g.getGoTypeName(u.Elem())
+	case *types.Map:
+		return "map[" + g.getGoTypeName(u.Key()) + "]" + g.getGoTypeName(u.Elem())
+	case *types.Interface:
+		return "interface{}"
+	default:
+		return name
+	}
 }
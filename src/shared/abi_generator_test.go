@@ -0,0 +1,148 @@
+package shared
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateFromPackage(t *testing.T) {
+	for _, pkgPath := range []string{
+		"./testdata/abiplugin",
+		"go-plugin-demo/src/shared/testdata/abiplugin",
+	} {
+		t.Run(pkgPath, func(t *testing.T) {
+			abi, err := NewABIGenerator().GenerateFromPackage("greeter", "1.0.0", pkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := MethodSpec{
+				Params:  []ParamSpec{{Name: "name", Type: "string"}, {Name: "times", Type: "int64"}},
+				Returns: "string,error",
+			}
+			if len(abi.Methods) != 1 || JoinParams(abi.Methods["Greet"].Params) != JoinParams(want.Params) ||
+				abi.Methods["Greet"].Returns != want.Returns {
+				t.Errorf("生成的方法为 %+v, 期望只有 Greet%+v", abi.Methods, want)
+			}
+		})
+	}
+
+	if _, err := NewABIGenerator().GenerateFromPackage("missing", "1.0.0", "./testdata/missing"); err == nil {
+		t.Error("不存在的包应返回错误")
+	}
+}
+
+func TestGenerateFromServe(t *testing.T) {
+	abi, err := NewABIGenerator().GenerateFromPackage("sample", "1.0.0", "./testdata/serveplugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PluginABI{
+		Name:    "sample",
+		Version: "2.1.0",
+		Methods: map[string]MethodSpec{
+			"Echo": {
+				Params:     []ParamSpec{{Name: "text", Type: "string", Description: "Text to echo"}},
+				Returns:    "string,error",
+				Help:       "Echoes text.",
+				Idempotent: true,
+			},
+			"Wait": {
+				Params:  []ParamSpec{{Name: "d", Type: "time.Duration", Optional: true, Default: "1s", Description: "How long to wait"}},
+				Options: []ParamSpec{{Name: "mode", Type: "string", Enum: []string{"fast", "slow"}}},
+				Returns: "void,error",
+			},
+			"Tags": {
+				Params:  []ParamSpec{{Type: "[]uint8"}, {Type: "time.Time"}, {Type: "map[string]any"}},
+				Returns: "map[string]int,error",
+			},
+			"Raw": {
+				Params:  []ParamSpec{{Name: "x", Type: "int64"}},
+				Returns: "float64,error",
+				Help:    "Calls a hand written function.",
+			},
+		},
+	}
+	if !reflect.DeepEqual(abi, want) {
+		t.Errorf("生成的ABI为 %+v, 期望 %+v", abi, want)
+	}
+
+	if _, err := NewABIGenerator().GenerateFromPackage("other", "1.0.0", "./testdata/serveplugin"); err == nil {
+		t.Error("插件名与源码不一致时应返回错误")
+	}
+}
+
+// 静态分析的结果应与插件运行时报告的ABI完全一致, 否则加载插件时描述文件校验会失败
+func TestGenerateFromPackageMatchesRuntime(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"calculator", "string_utils", "date_utils"} {
+		t.Run(name, func(t *testing.T) {
+			path := buildPlugin(t, dir, name)
+			pm := NewPluginManager()
+			defer pm.UnloadAll()
+			if err := pm.LoadPlugin(PluginConfig{Name: name, Path: path}); err != nil {
+				t.Fatal(err)
+			}
+			reported, _ := pm.ABI(name)
+
+			abi, err := NewABIGenerator().GenerateFromPackage(name, "", "go-plugin-demo/src/plugins/"+name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(abi, reported) {
+				t.Errorf("静态分析生成的ABI为 %+v, 插件报告的ABI为 %+v", abi, reported)
+			}
+		})
+	}
+}
+
+// checkSource 对不含导入的源码做类型检查
+func checkSource(t *testing.T, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "plugin.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("plugin", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestGenerateFromType(t *testing.T) {
+	pkg := checkSource(t, `package plugin
+
+type Protocol interface {
+	Invoke(method string, args ...interface{}) (interface{}, error)
+}
+
+type Settings struct{}
+
+type Greeter struct{}
+
+func (g *Greeter) Invoke(method string, args ...interface{}) (interface{}, error) { return nil, nil }
+func (g *Greeter) Greet(name string, times int) (string, error)                 { return name, nil }
+func (g *Greeter) Apply(s *Settings, tags []byte, extra map[string]float32)     {}
+func (g *Greeter) helper()                                                       {}
+`)
+	iface := pkg.Scope().Lookup("Protocol").Type().Underlying().(*types.Interface)
+	impl := findImplementation(pkg, iface)
+	if impl == nil || impl.String() != "*plugin.Greeter" {
+		t.Fatalf("findImplementation = %v, 期望 *plugin.Greeter", impl)
+	}
+
+	abi := NewABIGenerator().generateFromType("greeter", "1.0.0", impl, iface, true)
+	want := map[string]MethodSpec{
+		"Greet": {Params: []ParamSpec{{Name: "name", Type: "string"}, {Name: "times", Type: "int64"}}, Returns: "string,error"},
+		"Apply": {Params: []ParamSpec{{Name: "s", Type: "*Settings"}, {Name: "tags", Type: "[]uint32"},
+			{Name: "extra", Type: "map[string]float64"}}, Returns: "void"},
+	}
+	if abi.Name != "greeter" || abi.Version != "1.0.0" || !reflect.DeepEqual(abi.Methods, want) {
+		t.Errorf("generateFromType = %+v, 期望方法 %+v", abi, want)
+	}
+}
@@ -0,0 +1,182 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ABIManifestSuffix ABI描述文件名的后缀, 描述文件为 <插件路径>.abi.json
+const ABIManifestSuffix = ".abi.json"
+
+// abiManifest abi.json 文件结构, 格式见 docs/abi_spec.md
+type abiManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Exports struct {
+		Methods map[string]MethodSpec `json:"methods"`
+	} `json:"exports"`
+}
+
+// FindABIManifest 查找插件可执行文件对应的ABI描述文件 <插件路径>.abi.json
+// 同一目录下的多个插件各有自己的描述文件, 不读取共用的 abi.json
+func FindABIManifest(pluginPath string) (string, bool) {
+	path := pluginPath + ABIManifestSuffix
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		return path, true
+	}
+	return "", false
+}
+
+// ReadABIManifest 读取并校验ABI描述文件
+func ReadABIManifest(path string) (*PluginABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取ABI描述文件失败: %v", err)
+	}
+
+	var manifest abiManifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("解析ABI描述文件 %s 失败: %v", path, err)
+	}
+
+	abi := &PluginABI{
+		Name:    manifest.Name,
+		Version: manifest.Version,
+		Methods: manifest.Exports.Methods,
+	}
+	if err := ValidateABI(abi); err != nil {
+		return nil, fmt.Errorf("ABI描述文件 %s 无效: %v", path, err)
+	}
+	return abi, nil
+}
+
+// WriteABIManifest 将ABI描述按 docs/abi_spec.md 的格式写入文件
+func WriteABIManifest(path string, abi *PluginABI) error {
+	if err := ValidateABI(abi); err != nil {
+		return fmt.Errorf("ABI描述无效: %v", err)
+	}
+
+	var manifest abiManifest
+	manifest.Name = abi.Name
+	manifest.Version = abi.Version
+	manifest.Exports.Methods = abi.Methods
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化ABI描述失败: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入ABI描述文件失败: %v", err)
+	}
+	return nil
+}
+
+// ValidateABI 检查ABI描述是否完整
+func ValidateABI(abi *PluginABI) error {
+	if abi.Name == "" {
+		return errors.New("缺少插件名称")
+	}
+	if abi.Version == "" {
+		return errors.New("缺少插件版本")
+	}
+	if len(abi.Methods) == 0 {
+		return errors.New("没有导出任何方法")
+	}
+	for name, spec := range abi.Methods {
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("方法名 %q 不是合法的导出标识符", name)
+		}
+		if err := validateParams(spec.Params, true); err != nil {
+			return fmt.Errorf("方法 %s: %v", name, err)
+		}
+		if err := validateParams(spec.Options, false); err != nil {
+			return fmt.Errorf("方法 %s 的选项: %v", name, err)
+		}
+		if spec.Returns == "" {
+			return fmt.Errorf("方法 %s 缺少返回类型", name)
+		}
+	}
+	return nil
+}
+
+// CompareABI 比较ABI描述文件与插件运行时报告的ABI, 不一致时返回差异说明
+func CompareABI(manifest, reported *PluginABI) error {
+	var diffs []string
+	if manifest.Name != reported.Name {
+		diffs = append(diffs, fmt.Sprintf("名称 %q != %q", manifest.Name, reported.Name))
+	}
+	if manifest.Version != reported.Version {
+		diffs = append(diffs, fmt.Sprintf("版本 %q != %q", manifest.Version, reported.Version))
+	}
+
+	for name, spec := range manifest.Methods {
+		actual, ok := reported.Methods[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("插件未导出方法 %s", name))
+			continue
+		}
+		if !reflect.DeepEqual(paramSignatures(spec.Params), paramSignatures(actual.Params)) || spec.Returns != actual.Returns {
+			diffs = append(diffs, fmt.Sprintf("方法 %s 签名 (%s) %s != (%s) %s",
+				name, JoinParams(spec.Params), spec.Returns,
+				JoinParams(actual.Params), actual.Returns))
+		}
+		if !reflect.DeepEqual(paramSignatures(spec.Options), paramSignatures(actual.Options)) {
+			diffs = append(diffs, fmt.Sprintf("方法 %s 选项 {%s} != {%s}",
+				name, JoinParams(spec.Options), JoinParams(actual.Options)))
+		}
+		if spec.Idempotent != actual.Idempotent {
+			diffs = append(diffs, fmt.Sprintf("方法 %s 幂等标记 %v != %v", name, spec.Idempotent, actual.Idempotent))
+		}
+	}
+	for name := range reported.Methods {
+		if _, ok := manifest.Methods[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("ABI描述文件未声明方法 %s", name))
+		}
+	}
+
+	if len(diffs) == 0 {
+		return nil
+	}
+	sort.Strings(diffs)
+	return fmt.Errorf("ABI不一致: %s", strings.Join(diffs, "; "))
+}
+
+// paramSignatures 提取参数列表中影响调用方式的部分, nil与空列表视为相同
+func paramSignatures(params []ParamSpec) []string {
+	if len(params) == 0 {
+		return nil
+	}
+	sigs := make([]string, len(params))
+	for i, param := range params {
+		sigs[i] = param.signature()
+	}
+	return sigs
+}
+
+// JoinParams 以逗号连接参数的可读形式, 例如 "days int64, [layout string = 2006-01-02]"
+func JoinParams(params []ParamSpec) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readPluginManifest 若插件带有ABI描述文件, 读取并校验其格式, 没有描述文件时返回 nil
+// 在启动插件进程之前调用, 格式错误的描述文件不会让插件进程启动
+func readPluginManifest(pluginPath string) (*PluginABI, error) {
+	manifestPath, ok := FindABIManifest(pluginPath)
+	if !ok {
+		return nil, nil
+	}
+	return ReadABIManifest(manifestPath)
+}
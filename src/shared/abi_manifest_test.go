@@ -0,0 +1,153 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindABIManifest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"date_utils.abi.json", "abi.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "calculator.abi.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		plugin string
+		want   string // 为空表示没有描述文件
+	}{
+		{"date_utils", "date_utils.abi.json"},
+		// 目录下共用的 abi.json 不属于任何插件
+		{"string_utils", ""},
+		// 同名目录不是描述文件
+		{"calculator", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plugin, func(t *testing.T) {
+			path, ok := FindABIManifest(filepath.Join(dir, tt.plugin))
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(dir, tt.want)
+			}
+			if ok != (tt.want != "") || path != want {
+				t.Errorf("FindABIManifest = %q, %v, 期望 %q", path, ok, want)
+			}
+		})
+	}
+}
+
+func TestReadABIManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string // 为空表示读取成功
+	}{
+		{name: "有效", content: `{"name":"date_utils","version":"1.0.0","exports":{"methods":{"Now":{"returns":"time.Time"}}}}`},
+		{name: "不是JSON", content: `name: date_utils`, wantErr: "解析ABI描述文件"},
+		{name: "未知字段", content: `{"name":"date_utils","version":"1.0.0","author":"x"}`, wantErr: "解析ABI描述文件"},
+		{name: "缺少名称", content: `{"version":"1.0.0","exports":{"methods":{"Now":{"returns":"time.Time"}}}}`, wantErr: "缺少插件名称"},
+		{name: "缺少版本", content: `{"name":"date_utils","exports":{"methods":{"Now":{"returns":"time.Time"}}}}`, wantErr: "缺少插件版本"},
+		{name: "没有方法", content: `{"name":"date_utils","version":"1.0.0"}`, wantErr: "没有导出任何方法"},
+		{name: "方法名未导出", content: `{"name":"date_utils","version":"1.0.0","exports":{"methods":{"now":{"returns":"time.Time"}}}}`, wantErr: "不是合法的导出标识符"},
+		{name: "缺少返回类型", content: `{"name":"date_utils","version":"1.0.0","exports":{"methods":{"Now":{}}}}`, wantErr: "缺少返回类型"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "date_utils.abi.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			abi, err := ReadABIManifest(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadABIManifest 返回 %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if abi.Name != "date_utils" || abi.Methods["Now"].Returns != "time.Time" {
+				t.Errorf("ReadABIManifest = %+v", abi)
+			}
+		})
+	}
+}
+
+func TestCompareABI(t *testing.T) {
+	base := func() *PluginABI {
+		return &PluginABI{
+			Name:    "date_utils",
+			Version: "1.0.0",
+			Methods: map[string]MethodSpec{
+				"AddDays": {
+					Params:     []ParamSpec{{Name: "t", Type: "time.Time"}, {Name: "days", Type: "int64"}},
+					Options:    []ParamSpec{{Name: "layout", Type: "string", Optional: true, Default: "2006-01-02"}},
+					Returns:    "time.Time",
+					Idempotent: true,
+				},
+				"Now": {Returns: "time.Time"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(abi *PluginABI)
+		wantErr string // 为空表示ABI一致
+	}{
+		{name: "一致", modify: func(abi *PluginABI) {}},
+		{name: "帮助和描述不参与比较", modify: func(abi *PluginABI) {
+			spec := abi.Methods["AddDays"]
+			spec.Help = "其他说明"
+			spec.Params = []ParamSpec{{Name: "t", Type: "time.Time", Description: "起始时间"}, {Name: "days", Type: "int64"}}
+			abi.Methods["AddDays"] = spec
+		}},
+		{name: "空参数列表与nil相同", modify: func(abi *PluginABI) {
+			abi.Methods["Now"] = MethodSpec{Params: []ParamSpec{}, Returns: "time.Time"}
+		}},
+		{name: "名称不一致", modify: func(abi *PluginABI) { abi.Name = "calculator" }, wantErr: "名称"},
+		{name: "版本不一致", modify: func(abi *PluginABI) { abi.Version = "1.1.0" }, wantErr: "版本"},
+		{name: "缺少方法", modify: func(abi *PluginABI) { delete(abi.Methods, "Now") }, wantErr: "插件未导出方法 Now"},
+		{name: "多出方法", modify: func(abi *PluginABI) {
+			abi.Methods["Between"] = MethodSpec{Returns: "int64"}
+		}, wantErr: "ABI描述文件未声明方法 Between"},
+		{name: "参数类型不一致", modify: func(abi *PluginABI) {
+			spec := abi.Methods["AddDays"]
+			spec.Params = []ParamSpec{{Name: "t", Type: "time.Time"}, {Name: "days", Type: "int"}}
+			abi.Methods["AddDays"] = spec
+		}, wantErr: "方法 AddDays 签名"},
+		{name: "返回类型不一致", modify: func(abi *PluginABI) {
+			abi.Methods["Now"] = MethodSpec{Returns: "string"}
+		}, wantErr: "方法 Now 签名"},
+		{name: "选项不一致", modify: func(abi *PluginABI) {
+			spec := abi.Methods["AddDays"]
+			spec.Options = nil
+			abi.Methods["AddDays"] = spec
+		}, wantErr: "方法 AddDays 选项"},
+		{name: "幂等标记不一致", modify: func(abi *PluginABI) {
+			spec := abi.Methods["AddDays"]
+			spec.Idempotent = false
+			abi.Methods["AddDays"] = spec
+		}, wantErr: "幂等标记"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported := base()
+			tt.modify(reported)
+			err := CompareABI(base(), reported)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("CompareABI 返回 %v, 期望一致", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("CompareABI 返回 %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
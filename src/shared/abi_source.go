@@ -0,0 +1,480 @@
+package shared
+
+import (
+	"fmt"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// servePkgPath dynamic_plugin_shared 的导入路径, 用于识别 Serve、Wrap 等调用
+var servePkgPath = reflect.TypeOf(dynamic_plugin_shared.FuncSpec{}).PkgPath()
+
+// sourcePackage 做过类型检查的插件包源码
+type sourcePackage struct {
+	path  string
+	files []*ast.File
+	types *types.Package
+	info  *types.Info
+	vars  map[*types.Var]ast.Expr // 包级变量的初始值, 按需收集
+}
+
+// loadSourcePackage 解析插件包的源码并做类型检查
+// 依赖包从 go list -export 编译出的导出数据导入, 只有插件包自身从源码解析
+func loadSourcePackage(pkgPath string) (*sourcePackage, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedExportFile,
+	}, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载包 %s 失败: %v", pkgPath, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("加载包 %s 失败", pkgPath)
+	}
+	pkg, err := selectPackage(pkgs, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(pkg.GoFiles))
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", name, err)
+		}
+		files = append(files, file)
+	}
+
+	// 导出数据由当前的 go 命令编译, 使用同一版本标准库中的导入器读取
+	lookup := func(path string) (io.ReadCloser, error) {
+		dep, ok := pkg.Imports[path]
+		if !ok || dep.ExportFile == "" {
+			return nil, fmt.Errorf("没有找到 %s 的导出数据", path)
+		}
+		return os.Open(dep.ExportFile)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	config := &types.Config{Importer: importer.ForCompiler(fset, "gc", lookup)}
+	typesPkg, err := config.Check(pkg.PkgPath, fset, files, info)
+	if err != nil {
+		return nil, fmt.Errorf("包 %s 类型检查失败: %v", pkgPath, err)
+	}
+	return &sourcePackage{path: pkgPath, files: files, types: typesPkg, info: info}, nil
+}
+
+// lookup 在插件包直接导入的包中查找对象
+func (p *sourcePackage) lookup(pkgPath, name string) types.Object {
+	for _, imported := range p.types.Imports() {
+		if imported.Path() == pkgPath {
+			return imported.Scope().Lookup(name)
+		}
+	}
+	return nil
+}
+
+// callee 返回调用的 dynamic_plugin_shared 函数名, 调用的不是该包的函数时返回空
+func (p *sourcePackage) callee(call *ast.CallExpr) string {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return ""
+	}
+	fn, ok := p.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != servePkgPath {
+		return ""
+	}
+	return fn.Name()
+}
+
+// serveCall 查找包中对 dynamic_plugin_shared.Serve 的调用, 没有调用时返回 nil
+func (p *sourcePackage) serveCall() (*ast.CallExpr, error) {
+	var calls []*ast.CallExpr
+	for _, file := range p.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && p.callee(call) == "Serve" {
+				calls = append(calls, call)
+			}
+			return true
+		})
+	}
+	if len(calls) > 1 {
+		return nil, fmt.Errorf("包 %s 中有 %d 处调用 dynamic_plugin_shared.Serve, 只能有一处", p.path, len(calls))
+	}
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	return calls[0], nil
+}
+
+// resolve 将引用包级变量的标识符替换为变量的初始值, 函数表和参数描述常定义为包级变量
+func (p *sourcePackage) resolve(expr ast.Expr) ast.Expr {
+	expr = ast.Unparen(expr)
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return expr
+	}
+	v, ok := p.info.Uses[ident].(*types.Var)
+	if !ok || v.Parent() != p.types.Scope() {
+		return expr
+	}
+
+	if p.vars == nil {
+		p.vars = make(map[*types.Var]ast.Expr)
+		for _, file := range p.files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.ValueSpec)
+					if len(spec.Values) != len(spec.Names) {
+						continue
+					}
+					for i, name := range spec.Names {
+						if obj, ok := p.info.Defs[name].(*types.Var); ok {
+							p.vars[obj] = spec.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+	if value, ok := p.vars[v]; ok {
+		return p.resolve(value)
+	}
+	return expr
+}
+
+// compositeLit 返回表达式(或其引用的包级变量)的复合字面量
+func (p *sourcePackage) compositeLit(expr ast.Expr, what string) (*ast.CompositeLit, error) {
+	lit, ok := p.resolve(expr).(*ast.CompositeLit)
+	if !ok {
+		return nil, fmt.Errorf("%s 必须是字面量或以字面量初始化的包级变量", what)
+	}
+	return lit, nil
+}
+
+// fields 返回结构体字面量中各字段的值, 按字段名索引
+func (p *sourcePackage) fields(lit *ast.CompositeLit) map[string]ast.Expr {
+	values := make(map[string]ast.Expr, len(lit.Elts))
+	st, _ := p.info.TypeOf(lit).Underlying().(*types.Struct)
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				values[key.Name] = kv.Value
+			}
+		} else if st != nil && i < st.NumFields() {
+			values[st.Field(i).Name()] = elt
+		}
+	}
+	return values
+}
+
+// constValue 返回常量表达式的值, 不是常量时返回错误
+func (p *sourcePackage) constValue(expr ast.Expr, kind constant.Kind, what string) (constant.Value, error) {
+	value := p.info.Types[expr].Value
+	if value == nil || value.Kind() != kind {
+		return nil, fmt.Errorf("%s 必须是常量", what)
+	}
+	return value, nil
+}
+
+func (p *sourcePackage) constString(expr ast.Expr, what string) (string, error) {
+	value, err := p.constValue(expr, constant.String, what)
+	if err != nil {
+		return "", err
+	}
+	return constant.StringVal(value), nil
+}
+
+func (p *sourcePackage) constBool(expr ast.Expr, what string) (bool, error) {
+	value, err := p.constValue(expr, constant.Bool, what)
+	if err != nil {
+		return false, err
+	}
+	return constant.BoolVal(value), nil
+}
+
+// generateFromServe 按传给 Serve 的函数表生成ABI描述, 结果与插件运行时报告的ABI相同
+func (g *ABIGenerator) generateFromServe(pluginName string, pkg *sourcePackage, serve *ast.CallExpr) (*PluginABI, error) {
+	if len(serve.Args) < 3 {
+		return nil, fmt.Errorf("dynamic_plugin_shared.Serve 的参数不完整")
+	}
+	name, err := pkg.constString(serve.Args[0], "Serve 的插件名")
+	if err != nil {
+		return nil, err
+	}
+	if name != pluginName {
+		return nil, fmt.Errorf("包 %s 中的插件名为 %s, 与 %s 不一致", pkg.path, name, pluginName)
+	}
+	version, err := pkg.constString(serve.Args[1], "Serve 的版本号")
+	if err != nil {
+		return nil, err
+	}
+	table, err := pkg.compositeLit(serve.Args[2], "Serve 的函数表")
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]dynamic_plugin_shared.FuncSpec, 0, len(table.Elts))
+	for _, elt := range table.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("函数表必须是 map 字面量")
+		}
+		key, err := pkg.constString(kv.Key, "函数表的键")
+		if err != nil {
+			return nil, err
+		}
+		spec, err := pkg.funcSpec(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("导出函数 %s: %v", key, err)
+		}
+		if spec.Name == "" {
+			spec.Name = key
+		}
+		if spec.Name != key {
+			return nil, fmt.Errorf("函数表的键 %s 与函数名 %s 不一致", key, spec.Name)
+		}
+		exports = append(exports, spec)
+	}
+	return g.GenerateFromExports(name, version, exports)
+}
+
+// funcSpec 分析函数表中的一项, 可以是 Wrap(fn, DynamicFunc{...}) 或 DynamicFunc 字面量
+// Wrap 的参数类型、返回类型等按 WrapFunc 的规则由函数签名生成
+func (p *sourcePackage) funcSpec(expr ast.Expr) (dynamic_plugin_shared.FuncSpec, error) {
+	var spec dynamic_plugin_shared.FuncSpec
+	expr = p.resolve(expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if p.callee(call) != "Wrap" || len(call.Args) != 2 {
+			return spec, fmt.Errorf("只支持 dynamic_plugin_shared.Wrap 和 DynamicFunc 字面量")
+		}
+		lit, err := p.compositeLit(call.Args[1], "DynamicFunc")
+		if err != nil {
+			return spec, err
+		}
+		if spec, err = p.dynamicFunc(lit); err != nil {
+			return spec, err
+		}
+		sig, ok := p.info.TypeOf(call.Args[0]).Underlying().(*types.Signature)
+		if !ok {
+			return spec, fmt.Errorf("Wrap 的第一个参数不是函数")
+		}
+		return spec, wrapSignature(sig, &spec)
+	}
+
+	lit, err := p.compositeLit(expr, "DynamicFunc")
+	if err != nil {
+		return spec, err
+	}
+	return p.dynamicFunc(lit)
+}
+
+// dynamicFunc 读取 DynamicFunc 字面量中与ABI有关的字段, 值必须是常量或字面量
+func (p *sourcePackage) dynamicFunc(lit *ast.CompositeLit) (dynamic_plugin_shared.FuncSpec, error) {
+	var spec dynamic_plugin_shared.FuncSpec
+	var err error
+	for field, value := range p.fields(lit) {
+		switch field {
+		case "Name":
+			spec.Name, err = p.constString(value, field)
+		case "Help":
+			spec.Help, err = p.constString(value, field)
+		case "Returns":
+			spec.Returns, err = p.constString(value, field)
+		case "HasArgs":
+			spec.HasArgs, err = p.constBool(value, field)
+		case "HasOptions":
+			spec.HasOptions, err = p.constBool(value, field)
+		case "Idempotent":
+			spec.Idempotent, err = p.constBool(value, field)
+		case "Params":
+			spec.Params, err = p.params(value, field)
+		case "Options":
+			spec.Options, err = p.params(value, field)
+		}
+		if err != nil {
+			return spec, err
+		}
+	}
+	return spec, nil
+}
+
+// params 读取 []Param 字面量, nil 表示没有参数
+func (p *sourcePackage) params(expr ast.Expr, what string) ([]dynamic_plugin_shared.Param, error) {
+	if p.info.Types[expr].IsNil() {
+		return nil, nil
+	}
+	lit, err := p.compositeLit(expr, what)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]dynamic_plugin_shared.Param, len(lit.Elts))
+	for i, elt := range lit.Elts {
+		item, err := p.compositeLit(elt, what)
+		if err != nil {
+			return nil, err
+		}
+		param := &params[i]
+		for field, value := range p.fields(item) {
+			name := fmt.Sprintf("%s[%d].%s", what, i, field)
+			switch field {
+			case "Name":
+				param.Name, err = p.constString(value, name)
+			case "Type":
+				param.Type, err = p.constString(value, name)
+			case "Default":
+				param.Default, err = p.constString(value, name)
+			case "Description":
+				param.Description, err = p.constString(value, name)
+			case "Optional":
+				param.Optional, err = p.constBool(value, name)
+			case "Enum":
+				param.Enum, err = p.stringList(value, name)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return params, nil
+}
+
+// stringList 读取 []string 字面量
+func (p *sourcePackage) stringList(expr ast.Expr, what string) ([]string, error) {
+	if p.info.Types[expr].IsNil() {
+		return nil, nil
+	}
+	lit, err := p.compositeLit(expr, what)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(lit.Elts))
+	for i, elt := range lit.Elts {
+		if values[i], err = p.constString(elt, what); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// wrapSignature 按 WrapFunc 的规则检查函数签名, 设置参数类型、返回类型、HasArgs 和 HasOptions
+func wrapSignature(sig *types.Signature, spec *dynamic_plugin_shared.FuncSpec) error {
+	if sig.Variadic() {
+		return fmt.Errorf("不支持可变参数函数 %s", sig)
+	}
+	results := sig.Results()
+	switch {
+	case results.Len() == 0, results.Len() == 1 && isError(results.At(0).Type()):
+		spec.Returns = "void"
+	case results.Len() == 1, results.Len() == 2 && isError(results.At(1).Type()):
+		spec.Returns = wrapTypeName(results.At(0).Type())
+	default:
+		return fmt.Errorf("函数 %s 的返回值必须是 (T)、(error) 或 (T, error)", sig)
+	}
+
+	// 位置参数为 first 到 last-1
+	in := sig.Params()
+	first, last := 0, in.Len()
+	if last > 0 && isNamed(in.At(0).Type(), "context", "Context") {
+		first++
+	}
+	hasOptions := last > first && isNamed(in.At(last-1).Type(), servePkgPath, "Options")
+	if hasOptions {
+		last--
+	}
+	for i := first; i < last; i++ {
+		if isNamed(in.At(i).Type(), "context", "Context") {
+			return fmt.Errorf("函数 %s 的 context.Context 只能是第一个参数", sig)
+		}
+	}
+	numIn := last - first
+	if len(spec.Options) > 0 && !hasOptions {
+		return fmt.Errorf("声明了选项, 但函数 %s 的最后一个参数不是 Options", sig)
+	}
+	for _, option := range spec.Options {
+		if option.Name == "" || option.Type == "" {
+			return fmt.Errorf("选项必须声明名称和类型")
+		}
+	}
+	if len(spec.Params) > numIn {
+		return fmt.Errorf("描述了 %d 个参数, 但函数 %s 只有 %d 个", len(spec.Params), sig, numIn)
+	}
+
+	params := make([]dynamic_plugin_shared.Param, numIn)
+	copy(params, spec.Params)
+	for i := range params {
+		params[i].Type = wrapTypeName(in.At(first + i).Type())
+	}
+	spec.Params = params
+	spec.HasArgs = numIn > 0
+	spec.HasOptions = hasOptions
+	return nil
+}
+
+// wrapTypeName 返回静态类型在插件端ABI中的名称, 与 WrapFunc 按反射类型生成的名称一致
+func wrapTypeName(t types.Type) string {
+	t = types.Unalias(t)
+	switch {
+	case isNamed(t, "time", "Time"):
+		return "time.Time"
+	case isNamed(t, "time", "Duration"):
+		return "time.Duration"
+	}
+	if iface, ok := t.Underlying().(*types.Interface); ok && iface.NumMethods() == 0 {
+		return "any"
+	}
+
+	switch t := t.(type) {
+	case *types.Basic:
+		// byte、rune 在反射中为 uint8、int32
+		return types.Typ[t.Kind()].Name()
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil {
+			return pkg.Name() + "." + t.Obj().Name()
+		}
+		return t.Obj().Name()
+	case *types.Pointer:
+		return "*" + wrapTypeName(t.Elem())
+	case *types.Slice:
+		return "[]" + wrapTypeName(t.Elem())
+	case *types.Map:
+		return "map[" + wrapTypeName(t.Key()) + "]" + wrapTypeName(t.Elem())
+	default:
+		return types.TypeString(t, func(pkg *types.Package) string { return pkg.Name() })
+	}
+}
+
+// isNamed 判断类型是否为指定包中的具名类型
+func isNamed(t types.Type, pkgPath, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
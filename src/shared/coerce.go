@@ -0,0 +1,160 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoerceArgs 按方法的参数描述将命令行字符串转换为声明的类型
+// 省略的可选参数若有默认值则使用默认值, 否则不传; 参数无效时返回 CodeInvalidArgument
+func CoerceArgs(spec MethodSpec, raw []string) ([]interface{}, error) {
+	if len(raw) > len(spec.Params) {
+		return nil, NewError(CodeInvalidArgument, "参数过多: 最多 %d 个, 实际 %d 个", len(spec.Params), len(raw))
+	}
+
+	args := make([]interface{}, 0, len(spec.Params))
+	for i, param := range spec.Params {
+		if i >= len(raw) {
+			if !param.Optional {
+				return nil, NewError(CodeInvalidArgument, "缺少参数 %s", paramLabel(param, i))
+			}
+			if param.Default == "" {
+				break
+			}
+			value, err := CoerceValue(param, param.Default)
+			if err != nil {
+				return nil, NewError(CodeInvalidArgument, "参数 %s 的默认值无效: %v", paramLabel(param, i), err)
+			}
+			args = append(args, value)
+			continue
+		}
+
+		value, err := CoerceValue(param, raw[i])
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "参数 %s: %v", paramLabel(param, i), err)
+		}
+		args = append(args, value)
+	}
+	return args, nil
+}
+
+// CoerceOptions 按方法声明的关键字选项将命令行传入的字符串转换为对应类型
+// 未声明的选项和无效的值返回 CodeInvalidArgument; 未传入的选项不填充默认值, 由插件处理
+func CoerceOptions(spec MethodSpec, raw map[string]string) (map[string]interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	declared := make(map[string]ParamSpec, len(spec.Options))
+	for _, option := range spec.Options {
+		declared[option.Name] = option
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := make(map[string]interface{}, len(raw))
+	for _, name := range names {
+		option, ok := declared[name]
+		if !ok {
+			return nil, NewError(CodeInvalidArgument, "未知选项 %s", name)
+		}
+		value, err := CoerceValue(option, raw[name])
+		if err != nil {
+			return nil, NewError(CodeInvalidArgument, "选项 %s: %v", name, err)
+		}
+		options[name] = value
+	}
+	return options, nil
+}
+
+// CoerceValue 将字符串转换为参数声明的类型, 并检查允许的取值
+func CoerceValue(param ParamSpec, raw string) (interface{}, error) {
+	if len(param.Enum) > 0 && !containsString(param.Enum, raw) {
+		return nil, fmt.Errorf("值 %q 不在允许的取值 %v 中", raw, param.Enum)
+	}
+
+	value, err := parseTyped(param.Type, raw)
+	if err != nil {
+		return nil, fmt.Errorf("值 %q 不是有效的 %s: %v", raw, param.Type, err)
+	}
+	return value, nil
+}
+
+// parseTyped 按ABI类型名解析字符串, JSON对象解析为map, 其他未知类型原样保留为字符串
+func parseTyped(typeName, raw string) (interface{}, error) {
+	switch {
+	case typeName == "string", typeName == "any", typeName == "interface{}":
+		return raw, nil
+	case typeName == "bool":
+		return strconv.ParseBool(raw)
+	case typeName == "int":
+		v, err := strconv.ParseInt(raw, 10, 0)
+		return int(v), err
+	case typeName == "int32":
+		v, err := strconv.ParseInt(raw, 10, 32)
+		return int32(v), err
+	case typeName == "int64":
+		return strconv.ParseInt(raw, 10, 64)
+	case typeName == "uint32":
+		v, err := strconv.ParseUint(raw, 10, 32)
+		return uint32(v), err
+	case typeName == "uint64":
+		return strconv.ParseUint(raw, 10, 64)
+	case typeName == "float", typeName == "float64":
+		return strconv.ParseFloat(raw, 64)
+	case isTimeType(typeName):
+		return dynamic_plugin_shared.ParseTime(raw)
+	case isDurationType(typeName):
+		return time.ParseDuration(raw)
+	case typeName == "object", strings.HasPrefix(typeName, "map["):
+		var v map[string]interface{}
+		err := json.Unmarshal([]byte(raw), &v)
+		return v, err
+	case typeName == "array", strings.HasPrefix(typeName, "[]"):
+		var v []interface{}
+		err := json.Unmarshal([]byte(raw), &v)
+		return v, err
+	case strings.HasPrefix(typeName, "struct"), strings.HasPrefix(strings.TrimSpace(raw), "{"):
+		// 结构体写成JSON对象, 插件按字段的JSON标签还原;
+		// ABI中具名结构体与具名字符串类型同为 pkg.Name, 因此按值是否为对象区分
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("结构体需要写成JSON对象: %v", err)
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+func isTimeType(typeName string) bool {
+	switch typeName {
+	case "time.Time", "Time", "timestamp":
+		return true
+	}
+	return false
+}
+
+func isDurationType(typeName string) bool {
+	switch typeName {
+	case "time.Duration", "Duration", "duration":
+		return true
+	}
+	return false
+}
+
+// paramLabel 返回参数在错误信息中的名称
+func paramLabel(param ParamSpec, index int) string {
+	if param.Name != "" {
+		return param.Name
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
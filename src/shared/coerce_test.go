@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   ParamSpec
+		raw     string
+		want    interface{}
+		wantErr string // 为空表示转换成功
+	}{
+		{name: "字符串", param: ParamSpec{Type: "string"}, raw: "{x", want: "{x"},
+		{name: "any保持原样", param: ParamSpec{Type: "any"}, raw: "42", want: "42"},
+		{name: "布尔值", param: ParamSpec{Type: "bool"}, raw: "true", want: true},
+		{name: "int", param: ParamSpec{Type: "int"}, raw: "-3", want: -3},
+		{name: "int32越界", param: ParamSpec{Type: "int32"}, raw: "3000000000", wantErr: "不是有效的 int32"},
+		{name: "int64", param: ParamSpec{Type: "int64"}, raw: "7", want: int64(7)},
+		{name: "uint32", param: ParamSpec{Type: "uint32"}, raw: "7", want: uint32(7)},
+		{name: "uint64负数", param: ParamSpec{Type: "uint64"}, raw: "-1", wantErr: "不是有效的 uint64"},
+		{name: "float64", param: ParamSpec{Type: "float64"}, raw: "1.5", want: 1.5},
+		{name: "无效的数字", param: ParamSpec{Type: "float64"}, raw: "abc", wantErr: "不是有效的 float64"},
+		{name: "RFC3339时间", param: ParamSpec{Type: "time.Time"}, raw: "2024-01-02T03:04:05Z",
+			want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "日期", param: ParamSpec{Type: "time.Time"}, raw: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "无效的时间", param: ParamSpec{Type: "time.Time"}, raw: "昨天", wantErr: "不是有效的 time.Time"},
+		{name: "时长", param: ParamSpec{Type: "time.Duration"}, raw: "1m30s", want: 90 * time.Second},
+		{name: "map", param: ParamSpec{Type: "map[string]int64"}, raw: `{"a":1}`, want: map[string]interface{}{"a": 1.0}},
+		{name: "切片", param: ParamSpec{Type: "[]string"}, raw: `["a","b"]`, want: []interface{}{"a", "b"}},
+		{name: "无效的切片", param: ParamSpec{Type: "[]string"}, raw: "a,b", wantErr: "不是有效的 []string"},
+		{name: "具名结构体", param: ParamSpec{Type: "main.Point"}, raw: `{"x":1,"y":2}`,
+			want: map[string]interface{}{"x": 1.0, "y": 2.0}},
+		{name: "匿名结构体", param: ParamSpec{Type: "struct { X int }"}, raw: `{"X":1}`, want: map[string]interface{}{"X": 1.0}},
+		{name: "匿名结构体不是对象", param: ParamSpec{Type: "struct { X int }"}, raw: "1", wantErr: "结构体需要写成JSON对象"},
+		{name: "无效的对象", param: ParamSpec{Type: "main.Point"}, raw: `{"x":`, wantErr: "结构体需要写成JSON对象"},
+		{name: "具名字符串类型", param: ParamSpec{Type: "main.Level"}, raw: "debug", want: "debug"},
+		{name: "允许的取值", param: ParamSpec{Type: "string", Enum: []string{"asc", "desc"}}, raw: "desc", want: "desc"},
+		{name: "不允许的取值", param: ParamSpec{Type: "string", Enum: []string{"asc", "desc"}}, raw: "up", wantErr: "不在允许的取值"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceValue(tt.param, tt.raw)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("CoerceValue(%q) 返回 %v", tt.raw, err)
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CoerceValue(%q) 返回 %v, 期望包含 %q", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CoerceValue(%q) = %#v, 期望 %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoerceArgs(t *testing.T) {
+	spec := MethodSpec{Params: []ParamSpec{
+		{Name: "origin", Type: "main.Point"},
+		{Name: "scale", Type: "float64", Optional: true, Default: "1"},
+		{Name: "label", Type: "string", Optional: true},
+	}}
+
+	tests := []struct {
+		name    string
+		raw     []string
+		want    []interface{}
+		wantErr string
+	}{
+		{name: "使用默认值", raw: []string{`{"x":1}`}, want: []interface{}{map[string]interface{}{"x": 1.0}, 1.0}},
+		{name: "全部参数", raw: []string{`{}`, "2", "a"}, want: []interface{}{map[string]interface{}{}, 2.0, "a"}},
+		{name: "缺少参数", raw: nil, wantErr: "缺少参数 origin"},
+		{name: "参数过多", raw: []string{"{}", "1", "a", "b"}, wantErr: "参数过多"},
+		{name: "结构体参数无效", raw: []string{`{"x"`}, wantErr: "参数 origin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceArgs(spec, tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || CodeOf(err) != CodeInvalidArgument {
+					t.Fatalf("CoerceArgs 返回 %v (%s), 期望包含 %q 的 %s", err, CodeOf(err), tt.wantErr, CodeInvalidArgument)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CoerceArgs = %#v, 期望 %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package shared
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Config 插件配置文件结构
+type Config struct {
+	// PluginDir 插件目录, 目录下的每个可执行文件都会被当作插件加载
+	PluginDir string `json:"plugin_dir"`
+	// Sandbox 适用于所有插件的默认限制
+	Sandbox SandboxConfig `json:"sandbox"`
+	// Security 插件可执行文件的校验和连接加密要求
+	Security SecurityConfig `json:"security"`
+	Plugins  []PluginConfig `json:"plugins"`
+}
+
+// SecurityConfig 插件可执行文件的校验和连接加密要求
+type SecurityConfig struct {
+	// TrustedKeys 可信发布者的ed25519公钥, base64编码
+	TrustedKeys []string `json:"trusted_keys,omitempty"`
+	// RequireSignature 为 true 时拒绝启动没有签名的插件
+	RequireSignature bool `json:"require_signature,omitempty"`
+	// TLS 宿主与插件进程之间连接的双向TLS
+	TLS TLSConfig `json:"tls"`
+}
+
+// TLSConfig 宿主与插件进程之间连接的双向TLS配置
+// AutoMTLS 与自定义证书二选一; 都未设置时连接不加密
+type TLSConfig struct {
+	// AutoMTLS 每次启动插件时由 go-plugin 为双方生成临时证书
+	AutoMTLS bool `json:"auto_mtls,omitempty"`
+	// CAFile 签发宿主和插件证书的CA, PEM格式
+	CAFile string `json:"ca_file,omitempty"`
+	// CertFile 和 KeyFile 宿主的客户端证书和私钥
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	// PluginCertFile 和 PluginKeyFile 插件的服务端证书和私钥, 通过环境变量传给插件进程
+	PluginCertFile string `json:"plugin_cert_file,omitempty"`
+	PluginKeyFile  string `json:"plugin_key_file,omitempty"`
+	// ServerName 插件证书中的主机名, 默认为 localhost
+	ServerName string `json:"server_name,omitempty"`
+}
+
+// Enabled 是否启用了TLS
+func (c TLSConfig) Enabled() bool {
+	return c.AutoMTLS || c.CAFile != "" || c.CertFile != "" || c.KeyFile != "" ||
+		c.PluginCertFile != "" || c.PluginKeyFile != ""
+}
+
+// Validate 检查TLS配置是否完整
+func (c TLSConfig) Validate() error {
+	custom := c.CAFile != "" || c.CertFile != "" || c.KeyFile != "" || c.PluginCertFile != "" || c.PluginKeyFile != ""
+	if c.AutoMTLS && custom {
+		return fmt.Errorf("tls.auto_mtls 不能与自定义证书同时使用")
+	}
+	if custom && (c.CAFile == "" || c.CertFile == "" || c.KeyFile == "" || c.PluginCertFile == "" || c.PluginKeyFile == "") {
+		return fmt.Errorf("自定义TLS需要同时设置 ca_file、cert_file、key_file、plugin_cert_file 和 plugin_key_file")
+	}
+	return nil
+}
+
+// PublicKeys 解析可信公钥
+func (s SecurityConfig) PublicKeys() ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(s.TrustedKeys))
+	for _, k := range s.TrustedKeys {
+		key, err := ParsePublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SandboxConfig 插件运行限制
+type SandboxConfig struct {
+	// Timeout 单次调用的默认超时时间, 0 表示不限制
+	Timeout Duration `json:"timeout"`
+	// 插件进程的默认资源限制
+	ResourceLimits
+}
+
+// ResourceLimits 插件进程的资源限制, 零值表示不限制
+// Linux 上配置了 CgroupParent 时使用 cgroup 限制内存和进程数, 否则使用 rlimit
+type ResourceLimits struct {
+	// MemoryLimit 内存上限; cgroup 限制内存用量 (memory.max), rlimit 限制可写的私有内存 (RLIMIT_DATA)
+	MemoryLimit ByteSize `json:"memory_limit,omitempty"`
+	// CPUTime 累计CPU时间上限, 超过后插件进程被结束
+	CPUTime Duration `json:"cpu_time,omitempty"`
+	// MaxOpenFiles 打开文件描述符数量上限
+	MaxOpenFiles uint64 `json:"max_open_files,omitempty"`
+	// MaxProcesses 进程和线程数量上限, 由 cgroup 的 pids.max 限制;
+	// cgroup 不可用时使用 RLIMIT_NPROC, 它统计的是同一用户的全部进程, 不只是插件自身的进程
+	MaxProcesses uint64 `json:"max_processes,omitempty"`
+	// CgroupParent 委派给宿主的 cgroup v2 目录, 插件的cgroup创建在其下, 例如 systemd 的 Delegate=yes 服务所在的cgroup;
+	// 该目录需要已为子cgroup启用 memory 和 pids 控制器, 宿主不会修改它或移动任何进程。为空时不使用cgroup
+	CgroupParent string `json:"cgroup_parent,omitempty"`
+}
+
+// IsZero 是否没有设置任何限制, 只设置了 CgroupParent 不算
+func (l ResourceLimits) IsZero() bool {
+	l.CgroupParent = ""
+	return l == ResourceLimits{}
+}
+
+// Merge 用 override 中设置了的限制覆盖当前限制
+func (l ResourceLimits) Merge(override ResourceLimits) ResourceLimits {
+	if override.MemoryLimit > 0 {
+		l.MemoryLimit = override.MemoryLimit
+	}
+	if override.CPUTime > 0 {
+		l.CPUTime = override.CPUTime
+	}
+	if override.MaxOpenFiles > 0 {
+		l.MaxOpenFiles = override.MaxOpenFiles
+	}
+	if override.MaxProcesses > 0 {
+		l.MaxProcesses = override.MaxProcesses
+	}
+	if override.CgroupParent != "" {
+		l.CgroupParent = override.CgroupParent
+	}
+	return l
+}
+
+// PluginConfig 单个插件的配置
+type PluginConfig struct {
+	// Name 插件名, 必须与插件报告的名称(传给 Serve 的 name)一致
+	Name      string                   `json:"name"`
+	Path      string                   `json:"path"`
+	Handshake goplugin.HandshakeConfig `json:"handshake"` // 为空时使用 dynamic_plugin_shared.HandshakeConfig
+	// Timeout 该插件单次调用的超时时间, 覆盖 sandbox.timeout
+	Timeout Duration `json:"timeout,omitempty"`
+	// MethodTimeouts 按方法名覆盖超时时间
+	MethodTimeouts map[string]Duration `json:"method_timeouts,omitempty"`
+	// Limits 该插件的资源限制, 覆盖 sandbox 中的默认值
+	Limits ResourceLimits `json:"limits"`
+	// Checksum 可执行文件的SHA-256摘要, 十六进制; 为空时使用签名描述文件中的值
+	Checksum string `json:"checksum,omitempty"`
+	// Signature 可信发布者对SHA-256摘要的ed25519签名, base64编码; 为空时使用签名描述文件中的值
+	Signature string `json:"signature,omitempty"`
+}
+
+// ReadConfig 读取并解析插件配置文件
+func ReadConfig(configPath string) (*Config, error) {
+	configFile, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件配置文件失败: %v", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(configFile, &config); err != nil {
+		return nil, fmt.Errorf("解析插件配置失败: %v", err)
+	}
+	if _, err := config.Security.PublicKeys(); err != nil {
+		return nil, fmt.Errorf("解析插件配置失败: %v", err)
+	}
+	if err := config.Security.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("解析插件配置失败: %v", err)
+	}
+	return &config, nil
+}
+
+// Duration 配置文件中的时间长度, 以 "5s"、"1m30s" 这样的字符串表示
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("时间长度必须是字符串, 例如 \"5s\": %v", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("无效的时间长度 %q: %v", s, err)
+	}
+	if v < 0 {
+		return fmt.Errorf("时间长度 %q 不能为负数", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ByteSize 配置文件中的字节数, 以 "256MB"、"1GiB" 这样的字符串或整数表示, 单位按1024进位
+type ByteSize uint64
+
+var byteUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+}
+
+// ParseByteSize 解析带单位的字节数
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("无效的字节数 %q", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的字节数 %q", s)
+	}
+	return ByteSize(n * float64(unit)), nil
+}
+
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1<<30 && b%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", b>>30)
+	case b >= 1<<20 && b%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", b>>20)
+	case b >= 1<<10 && b%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", b>>10)
+	default:
+		return fmt.Sprintf("%dB", uint64(b))
+	}
+}
+
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var n uint64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = ByteSize(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("字节数必须是整数或字符串, 例如 \"256MB\": %v", err)
+	}
+	v, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
@@ -0,0 +1,35 @@
+package shared
+
+import (
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+)
+
+// Error 插件调用的结构化错误, 与插件端的 dynamic_plugin_shared.Error 是同一类型
+// 宿主可以用 errors.As 取出错误码和详情, 或用 errors.Is(err, CodeNotFound) 按错误码判断
+type Error = dynamic_plugin_shared.Error
+
+// ErrorCode 错误分类
+type ErrorCode = dynamic_plugin_shared.ErrorCode
+
+const (
+	CodeNotFound        = dynamic_plugin_shared.CodeNotFound
+	CodeInvalidArgument = dynamic_plugin_shared.CodeInvalidArgument
+	CodeInternal        = dynamic_plugin_shared.CodeInternal
+	CodeUnavailable     = dynamic_plugin_shared.CodeUnavailable
+	CodeTimeout         = dynamic_plugin_shared.CodeTimeout
+)
+
+// CodeOf 返回错误的错误码, 规则见 dynamic_plugin_shared.CodeOf
+func CodeOf(err error) ErrorCode {
+	return dynamic_plugin_shared.CodeOf(err)
+}
+
+// NewError 创建指定错误码的错误
+func NewError(code ErrorCode, format string, args ...interface{}) *Error {
+	return dynamic_plugin_shared.NewError(code, format, args...)
+}
+
+// WrapError 以 err 的文本为消息创建指定错误码的错误, errors.Is/As 仍可以找到 err
+func WrapError(code ErrorCode, err error) *Error {
+	return dynamic_plugin_shared.WrapError(code, err)
+}
@@ -0,0 +1,224 @@
+package shared
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// SignatureManifestSuffix 签名描述文件的后缀, 签名描述文件与插件可执行文件放在同一目录
+const SignatureManifestSuffix = ".sig.json"
+
+// SignatureManifest 插件可执行文件的校验和与签名
+type SignatureManifest struct {
+	// Name 签名时的插件名, 仅用于提示; 校验时使用宿主加载插件的名称
+	Name string `json:"name,omitempty"`
+	// SHA256 可执行文件的SHA-256摘要, 十六进制
+	SHA256 string `json:"sha256"`
+	// Signature 发布者私钥对插件名和SHA-256摘要的ed25519签名, base64编码, 签名内容见 signedMessage
+	Signature string `json:"signature,omitempty"`
+	// KeyID 签名公钥的标识, 仅用于提示
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// SignatureManifestPath 返回插件的签名描述文件路径
+func SignatureManifestPath(pluginPath string) string {
+	return pluginPath + SignatureManifestSuffix
+}
+
+// ReadSignatureManifest 读取签名描述文件
+func ReadSignatureManifest(path string) (*SignatureManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SignatureManifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("解析签名描述文件 %s 失败: %v", path, err)
+	}
+	return &manifest, nil
+}
+
+// WriteSignatureManifest 写入签名描述文件
+func WriteSignatureManifest(path string, manifest *SignatureManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化签名描述失败: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入签名描述文件失败: %v", err)
+	}
+	return nil
+}
+
+// FileSHA256 计算文件的SHA-256摘要
+func FileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// signedMessage 签名的内容: 插件名、一个0字节和SHA-256摘要
+// 签名同时绑定插件名, 一个插件的签名不能用于以其他名称加载的可执行文件
+func signedMessage(name string, digest []byte) []byte {
+	return append(append([]byte(name), 0), digest...)
+}
+
+// SignPlugin 计算插件可执行文件的摘要, 用发布者私钥对插件名和摘要签名
+// name 必须与宿主加载插件时使用的名称一致: 配置文件中的 name, 或插件目录中的文件名
+func SignPlugin(name, pluginPath string, key ed25519.PrivateKey) (*SignatureManifest, error) {
+	if name == "" {
+		return nil, fmt.Errorf("插件名不能为空")
+	}
+	digest, err := FileSHA256(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件失败: %v", err)
+	}
+	return &SignatureManifest{
+		Name:      name,
+		SHA256:    hex.EncodeToString(digest),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, signedMessage(name, digest))),
+		KeyID:     KeyID(key.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// VerifyPlugin 在启动插件前校验名为 name 的插件的可执行文件
+// 配置中的 checksum 和 signature 优先, 未配置的项从签名描述文件中读取;
+// 有校验和时必须与文件一致, 有签名时必须由可信公钥之一对 name 和摘要签署, RequireSignature 时必须有签名。
+// 需要校验时返回校验所读取内容的私有副本, 宿主从副本启动插件, 校验之后替换或改写可执行文件不影响启动的内容;
+// 副本在插件进程启动后由调用方用 releasePinned 释放。没有校验和与签名时返回 nil, 从原路径启动
+func VerifyPlugin(name, pluginPath, checksum, signature string, security SecurityConfig) (*os.File, error) {
+	manifest, err := ReadSignatureManifest(SignatureManifestPath(pluginPath))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if manifest != nil {
+		if checksum == "" {
+			checksum = manifest.SHA256
+		}
+		if signature == "" {
+			signature = manifest.Signature
+		}
+	}
+
+	if signature == "" && security.RequireSignature {
+		return nil, fmt.Errorf("插件 %s 未签名, 配置要求所有插件必须签名", pluginPath)
+	}
+	if checksum == "" && signature == "" {
+		return nil, nil
+	}
+
+	// 从打开的文件复制出副本并同时计算摘要, 校验的内容就是之后启动的内容
+	pinned, digest, err := pinPlugin(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件失败: %v", err)
+	}
+	if err := verifyDigest(name, pluginPath, digest, checksum, signature, security); err != nil {
+		releasePinned(pinned)
+		return nil, err
+	}
+	return pinned, nil
+}
+
+// verifyDigest 校验摘要与校验和一致, 且签名由可信公钥之一对插件名和摘要签署
+func verifyDigest(name, pluginPath string, digest []byte, checksum, signature string, security SecurityConfig) error {
+	if checksum != "" {
+		expected, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(checksum), "sha256:"))
+		if err != nil || len(expected) != sha256.Size {
+			return fmt.Errorf("无效的SHA-256校验和 %q", checksum)
+		}
+		if !bytes.Equal(expected, digest) {
+			return fmt.Errorf("插件 %s 校验和不匹配: 期望 %x, 实际 %x", pluginPath, expected, digest)
+		}
+	}
+
+	if signature != "" {
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return fmt.Errorf("无效的签名: %v", err)
+		}
+		keys, err := security.PublicKeys()
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("插件 %s 带有签名, 但没有配置可信公钥", pluginPath)
+		}
+		message := signedMessage(name, digest)
+		for _, key := range keys {
+			if ed25519.Verify(key, message, sig) {
+				return nil
+			}
+		}
+		return fmt.Errorf("插件 %s 的签名无效、不是由可信公钥签署或不是插件 %s 的签名", pluginPath, name)
+	}
+	return nil
+}
+
+// GenerateKey 生成发布者的ed25519密钥对
+func GenerateKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// KeyID 返回公钥的短标识
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// EncodePublicKey 将公钥编码为配置文件中使用的base64字符串
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey 解析base64编码的ed25519公钥
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("无效的ed25519公钥 %q", s)
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// WritePrivateKey 以base64编码写入私钥文件, 文件仅所有者可读
+func WritePrivateKey(path string, key ed25519.PrivateKey) error {
+	data := base64.StdEncoding.EncodeToString(key) + "\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		return fmt.Errorf("写入私钥失败: %v", err)
+	}
+	return nil
+}
+
+// ReadPrivateKey 读取 WritePrivateKey 写入的私钥文件
+func ReadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥失败: %v", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("无效的ed25519私钥文件 %s", path)
+	}
+	return ed25519.PrivateKey(key), nil
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package shared
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// pinPlugin 将插件复制到密封的匿名内存文件中, 同时计算SHA-256摘要
+// 密封后任何进程都不能再修改副本的内容
+func pinPlugin(path string) (*os.File, []byte, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	name := "plugin:" + filepath.Base(path)
+	fd, err := unix.MemfdCreate(name, unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING|unix.MFD_EXEC)
+	if errors.Is(err, unix.EINVAL) {
+		// 内核早于6.3时不支持 MFD_EXEC, 内存文件默认可执行
+		fd, err = unix.MemfdCreate(name, unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	f := os.NewFile(uintptr(fd), name)
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), src); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, h.Sum(nil), nil
+}
+
+// pinnedCommand 返回启动副本使用的路径和需要传给子进程的文件
+// 副本作为 ExtraFiles 的第一个文件, 在子进程中是文件描述符3; 插件进程会继承这个只读的描述符
+func pinnedCommand(pinned *os.File) (string, []*os.File) {
+	return "/proc/self/fd/3", []*os.File{pinned}
+}
+
+// releasePinned 插件进程启动后释放宿主持有的副本
+func releasePinned(pinned *os.File) {
+	if pinned != nil {
+		pinned.Close()
+	}
+}
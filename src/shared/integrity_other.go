@@ -0,0 +1,57 @@
+//go:build !linux
+
+package shared
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// pinPlugin 将插件复制到只有当前用户可以访问的临时目录中, 同时计算SHA-256摘要
+func pinPlugin(path string) (*os.File, []byte, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	dir, err := os.MkdirTemp("", "plugin-")
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, filepath.Base(path)), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0700)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), src)
+	f.Close()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	// 以只读方式持有副本, 正在写入的文件不能被执行
+	pinned, err := os.Open(f.Name())
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	return pinned, h.Sum(nil), nil
+}
+
+// pinnedCommand 返回启动副本使用的路径和需要传给子进程的文件
+func pinnedCommand(pinned *os.File) (string, []*os.File) {
+	return pinned.Name(), nil
+}
+
+// releasePinned 插件进程启动后删除副本, 无法删除运行中可执行文件的平台上副本留在临时目录中
+func releasePinned(pinned *os.File) {
+	if pinned != nil {
+		pinned.Close()
+		os.RemoveAll(filepath.Dir(pinned.Name()))
+	}
+}
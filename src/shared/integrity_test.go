@@ -0,0 +1,173 @@
+package shared
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyPlugin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "date_utils")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := FileSHA256(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := hex.EncodeToString(digest)
+
+	trustedPub, trusted, _ := GenerateKey()
+	_, untrusted, _ := GenerateKey()
+	sign := func(name string, key ed25519.PrivateKey) string {
+		manifest, err := SignPlugin(name, path, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return manifest.Signature
+	}
+	withKeys := SecurityConfig{TrustedKeys: []string{EncodePublicKey(trustedPub)}}
+	requireSignature := withKeys
+	requireSignature.RequireSignature = true
+
+	tests := []struct {
+		name      string
+		checksum  string
+		signature string
+		security  SecurityConfig
+		wantErr   string // 为空表示校验通过
+		wantPin   bool
+	}{
+		{name: "不校验", wantPin: false},
+		{name: "要求签名但未签名", security: requireSignature, wantErr: "未签名"},
+		{name: "校验和一致", checksum: checksum, wantPin: true},
+		{name: "带前缀的大写校验和", checksum: "sha256:" + strings.ToUpper(checksum), wantPin: true},
+		{name: "校验和不一致", checksum: strings.Repeat("0", 64), wantErr: "校验和不匹配"},
+		{name: "无效的校验和", checksum: "xyz", wantErr: "无效的SHA-256校验和"},
+		{name: "可信签名", signature: sign("date_utils", trusted), security: requireSignature, wantPin: true},
+		{name: "校验和与签名", checksum: checksum, signature: sign("date_utils", trusted), security: withKeys, wantPin: true},
+		{name: "其他插件名的签名", signature: sign("calculator", trusted), security: withKeys, wantErr: "签名无效"},
+		{name: "不可信的签名", signature: sign("date_utils", untrusted), security: withKeys, wantErr: "签名无效"},
+		{name: "没有可信公钥", signature: sign("date_utils", trusted), wantErr: "没有配置可信公钥"},
+		{name: "无效的签名编码", signature: "!", security: withKeys, wantErr: "无效的签名"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinned, err := VerifyPlugin("date_utils", path, tt.checksum, tt.signature, tt.security)
+			defer releasePinned(pinned)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("校验失败: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("错误为 %v, 期望包含 %q", err, tt.wantErr)
+			}
+			if (pinned != nil) != tt.wantPin {
+				t.Errorf("返回的副本为 %v, 期望返回副本: %v", pinned, tt.wantPin)
+			}
+		})
+	}
+}
+
+func TestVerifyPluginManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "string_utils")
+	if err := os.WriteFile(path, []byte("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pub, key, _ := GenerateKey()
+	manifest, err := SignPlugin("string_utils", path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSignatureManifest(SignatureManifestPath(path), manifest); err != nil {
+		t.Fatal(err)
+	}
+	security := SecurityConfig{TrustedKeys: []string{EncodePublicKey(pub)}, RequireSignature: true}
+
+	pinned, err := VerifyPlugin("string_utils", path, "", "", security)
+	if err != nil {
+		t.Fatalf("使用签名描述文件校验失败: %v", err)
+	}
+	defer releasePinned(pinned)
+
+	// 以其他名称加载同一个文件时签名不成立
+	if other, err := VerifyPlugin("other", path, "", "", security); err == nil {
+		releasePinned(other)
+		t.Error("签名描述文件的签名不应对其他插件名有效")
+	}
+
+	// 校验之后改写原文件不影响副本的内容
+	if err := os.WriteFile(path, []byte("v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pinned.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(pinned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v1" {
+		t.Errorf("副本内容为 %q, 期望校验时的 v1", content)
+	}
+	if _, err := VerifyPlugin("string_utils", path, "", "", security); err == nil {
+		t.Error("改写后的文件不应通过校验")
+	}
+}
+
+func TestLoadVerifiedPlugin(t *testing.T) {
+	path := buildPlugin(t, t.TempDir(), "calculator")
+	digest, err := FileSHA256(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, limits := range []ResourceLimits{{}, {MaxOpenFiles: 256}} {
+		t.Run(fmt.Sprintf("%+v", limits), func(t *testing.T) {
+			pm := NewPluginManager()
+			defer pm.UnloadAll()
+			err := pm.LoadPlugin(PluginConfig{
+				Name:     "calculator",
+				Path:     path,
+				Checksum: hex.EncodeToString(digest),
+				Limits:   limits,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := pm.Invoke("calculator", "Multiply", 6.0, 7.0)
+			if err != nil || fmt.Sprint(result) != "42" {
+				t.Errorf("calculator.Multiply = %v, %v, 期望 42", result, err)
+			}
+		})
+	}
+}
+
+func TestPrivateKeyFile(t *testing.T) {
+	_, key, _ := GenerateKey()
+	path := filepath.Join(t.TempDir(), "plugin.key")
+	if err := WritePrivateKey(path, key); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("私钥文件权限为 %v, %v, 期望 0600", info.Mode().Perm(), err)
+	}
+	read, err := ReadPrivateKey(path)
+	if err != nil || !read.Equal(key) {
+		t.Errorf("ReadPrivateKey 返回 %v, 与写入的私钥不一致", err)
+	}
+
+	if _, err := ParsePublicKey("不是公钥"); err == nil {
+		t.Error("ParsePublicKey 应拒绝无效的公钥")
+	}
+	pub := key.Public().(ed25519.PublicKey)
+	if parsed, err := ParsePublicKey(EncodePublicKey(pub)); err != nil || !parsed.Equal(pub) {
+		t.Errorf("ParsePublicKey 返回 %v, 与原公钥不一致", err)
+	}
+}
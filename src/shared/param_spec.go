@@ -0,0 +1,120 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParamSpec 描述方法的一个参数或关键字选项
+// 在JSON中既可以写成完整对象, 也可以只写类型字符串(兼容 docs/abi_spec.md v1.0 的格式)
+type ParamSpec struct {
+	Name        string   `json:"name,omitempty"`
+	Type        string   `json:"type"`
+	Optional    bool     `json:"optional,omitempty"`
+	Default     string   `json:"default,omitempty"` // 默认值的文本形式, 按 Type 解析
+	Enum        []string `json:"enum,omitempty"`    // 允许的取值, 为空表示不限制
+	Description string   `json:"description,omitempty"`
+}
+
+// ParamTypes 由类型名列表构造只包含类型的参数描述
+func ParamTypes(types ...string) []ParamSpec {
+	params := make([]ParamSpec, len(types))
+	for i, t := range types {
+		params[i] = ParamSpec{Type: t}
+	}
+	return params
+}
+
+// paramSpecJSON 避免 MarshalJSON/UnmarshalJSON 递归
+type paramSpecJSON ParamSpec
+
+func (p ParamSpec) MarshalJSON() ([]byte, error) {
+	// 只有类型时保持紧凑的字符串格式
+	if p.Name == "" && !p.Optional && p.Default == "" && len(p.Enum) == 0 && p.Description == "" {
+		return json.Marshal(p.Type)
+	}
+	return json.Marshal(paramSpecJSON(p))
+}
+
+func (p *ParamSpec) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		*p = ParamSpec{}
+		return json.Unmarshal(data, &p.Type)
+	}
+
+	var spec paramSpecJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return err
+	}
+	*p = ParamSpec(spec)
+	return nil
+}
+
+// String 返回参数的可读形式, 例如 "days int64"、"[layout string = 2006-01-02]"
+func (p ParamSpec) String() string {
+	s := p.Type
+	if p.Name != "" {
+		s = p.Name + " " + p.Type
+	}
+	if len(p.Enum) > 0 {
+		s += " {" + strings.Join(p.Enum, "|") + "}"
+	}
+	if p.Default != "" {
+		s += " = " + p.Default
+	}
+	if p.Optional {
+		s = "[" + s + "]"
+	}
+	return s
+}
+
+// signature 参数中影响调用方式的部分, 用于比较ABI
+func (p ParamSpec) signature() string {
+	return fmt.Sprintf("%s %s optional=%t", p.Name, p.Type, p.Optional)
+}
+
+// validateParams 检查参数列表: 类型必填, 可选参数只能位于末尾, 默认值必须在允许的取值内
+func validateParams(params []ParamSpec, positional bool) error {
+	optional := false
+	names := make(map[string]bool)
+	for i, param := range params {
+		label := fmt.Sprintf("第 %d 个参数", i+1)
+		if param.Name != "" {
+			label = fmt.Sprintf("参数 %s", param.Name)
+			if names[param.Name] {
+				return fmt.Errorf("%s 重复", label)
+			}
+			names[param.Name] = true
+		} else if !positional {
+			return fmt.Errorf("第 %d 个选项缺少名称", i+1)
+		}
+
+		if param.Type == "" {
+			return fmt.Errorf("%s 缺少类型", label)
+		}
+		if positional {
+			if optional && !param.Optional {
+				return fmt.Errorf("%s 是必选参数, 不能位于可选参数之后", label)
+			}
+			optional = optional || param.Optional
+		}
+		if param.Default != "" && len(param.Enum) > 0 && !containsString(param.Enum, param.Default) {
+			return fmt.Errorf("%s 的默认值 %q 不在允许的取值 %v 中", label, param.Default, param.Enum)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
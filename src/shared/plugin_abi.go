@@ -1,7 +1,9 @@
 package shared
 
 import (
+	"context"
 	"fmt"
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
 	"net/rpc"
 
 	"github.com/hashicorp/go-plugin"
@@ -16,8 +18,12 @@ type PluginABI struct {
 
 // MethodSpec 描述方法签名
 type MethodSpec struct {
-	Params  []string `json:"params"`
-	Returns string   `json:"returns"`
+	Params  []ParamSpec `json:"params"`
+	Options []ParamSpec `json:"options,omitempty"` // 关键字选项
+	Returns string      `json:"returns"`
+	Help    string      `json:"help,omitempty"`
+	// Idempotent 方法可以安全地重复调用, 插件崩溃重启后宿主会重试进行中的调用
+	Idempotent bool `json:"idempotent,omitempty"`
 }
 
 // PluginDescriptor 插件描述文件结构
@@ -28,62 +34,194 @@ type PluginDescriptor struct {
 
 // DynamicPlugin 动态插件接口
 type DynamicPlugin interface {
-	plugin.Plugin
 	GetABI() (*PluginABI, error)
 	Invoke(method string, args ...interface{}) (interface{}, error)
 }
 
+// ContextPlugin 支持上下文的动态插件, 调用的截止时间会传给插件进程
+type ContextPlugin interface {
+	DynamicPlugin
+	InvokeContext(ctx context.Context, method string, args ...interface{}) (interface{}, error)
+}
+
+// OptionsPlugin 支持关键字选项的动态插件
+type OptionsPlugin interface {
+	DynamicPlugin
+	InvokeOptions(ctx context.Context, method string, args []interface{}, options map[string]interface{}) (interface{}, error)
+}
+
+// HelpPlugin 可以向插件进程查询方法帮助信息的动态插件
+type HelpPlugin interface {
+	DynamicPlugin
+	Help(method string) (string, error)
+}
+
 // DynamicPluginRPC RPC实现
 type DynamicPluginRPC struct {
 	Impl DynamicPlugin
 }
 
 func (p *DynamicPluginRPC) Server(*plugin.MuxBroker) (interface{}, error) {
-	return p.Impl, nil
+	return &DynamicPluginRPCServer{Impl: p.Impl}, nil
 }
 
 func (p *DynamicPluginRPC) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
-	return &DynamicPluginRPC{Impl: p.Impl}, nil
+	return &DynamicPluginRPCClient{client: c}, nil
+}
+
+// DynamicPluginRPCClient 宿主端RPC客户端
+type DynamicPluginRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *DynamicPluginRPCClient) GetABI() (*PluginABI, error) {
+	var resp PluginABI
+	if err := c.client.Call("Plugin.GetABI", new(interface{}), &resp); err != nil {
+		return nil, dynamic_plugin_shared.DecodeRPCError(err)
+	}
+	return &resp, nil
+}
+
+func (c *DynamicPluginRPCClient) Invoke(method string, args ...interface{}) (interface{}, error) {
+	return c.InvokeContext(context.Background(), method, args...)
 }
 
-func (p *DynamicPluginRPC) GetABI(args interface{}, resp *PluginABI) error {
-	// 自动生成ABI
-	generator := NewABIGenerator()
-	abi, err := generator.GenerateFromInstance("plugin", "1.0.0", p.Impl)
+// InvokeContext 上下文的截止时间随请求传给插件; 上下文结束时立即返回, 不再等待插件响应
+func (c *DynamicPluginRPCClient) InvokeContext(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	argValues, err := dynamic_plugin_shared.ToValues(args)
 	if err != nil {
-		return err
+		return nil, NewError(CodeInvalidArgument, "参数无法编码: %v", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	var resp dynamic_plugin_shared.Value
+	call := c.client.Go("Plugin.Invoke", dynamic_plugin_shared.InvokeArgs{
+		Method:   method,
+		Args:     argValues,
+		Deadline: deadline,
+	}, &resp, nil)
+
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			return nil, dynamic_plugin_shared.DecodeRPCError(call.Error)
+		}
+		return resp.Interface(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DynamicPluginRPCServer 插件端RPC服务
+type DynamicPluginRPCServer struct {
+	Impl DynamicPlugin
+}
+
+func (s *DynamicPluginRPCServer) GetABI(args interface{}, resp *PluginABI) error {
+	abi, err := s.Impl.GetABI()
+	if err != nil {
+		return dynamic_plugin_shared.EncodeRPCError(err)
 	}
 	*resp = *abi
 	return nil
 }
 
-func (p *DynamicPluginRPC) Invoke(args []interface{}, resp *interface{}) error {
-	if len(args) < 1 {
-		return fmt.Errorf("至少需要方法名参数")
-	}
-	method, ok := args[0].(string)
-	if !ok {
-		return fmt.Errorf("第一个参数必须是方法名")
+// Invoke 返回的错误经 EncodeRPCError 编码, 宿主端可以还原错误码
+func (s *DynamicPluginRPCServer) Invoke(args dynamic_plugin_shared.InvokeArgs, resp *dynamic_plugin_shared.Value) error {
+	if args.Method == "" {
+		return dynamic_plugin_shared.EncodeRPCError(NewError(CodeInvalidArgument, "缺少方法名"))
 	}
 
-	result, err := p.Impl.Invoke(method, args[1:]...)
+	ctx, cancel := dynamic_plugin_shared.DeadlineContext(args.Deadline)
+	defer cancel()
+
+	result, err := InvokeContext(ctx, s.Impl, args.Method, dynamic_plugin_shared.FromValues(args.Args)...)
 	if err != nil {
-		return err
+		return dynamic_plugin_shared.EncodeRPCError(err)
 	}
-	*resp = result
+	value, err := dynamic_plugin_shared.ToValue(result)
+	if err != nil {
+		return dynamic_plugin_shared.EncodeRPCError(NewError(CodeInternal, "返回值无法编码: %v", err))
+	}
+	*resp = value
 	return nil
 }
 
+// exportsPlugin 将 dynamic_plugin_shared 协议的插件适配为 DynamicPlugin
+type exportsPlugin struct {
+	impl dynamic_plugin_shared.DynamicPluginInterface
+}
+
+// GetABI 根据插件导出的函数表生成ABI描述, 名称和版本为插件报告的值
+func (p *exportsPlugin) GetABI() (*PluginABI, error) {
+	exports, err := p.impl.Exports()
+	if err != nil {
+		return nil, err
+	}
+	return NewABIGenerator().GenerateFromExports(p.impl.Name(), p.impl.Version(), exports)
+}
+
+func (p *exportsPlugin) Invoke(method string, args ...interface{}) (interface{}, error) {
+	return p.impl.Invoke(method, args, nil)
+}
+
+func (p *exportsPlugin) InvokeContext(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	return dynamic_plugin_shared.InvokeContext(ctx, p.impl, method, args, nil)
+}
+
+func (p *exportsPlugin) InvokeOptions(ctx context.Context, method string, args []interface{}, options map[string]interface{}) (interface{}, error) {
+	return dynamic_plugin_shared.InvokeContext(ctx, p.impl, method, args, options)
+}
+
+func (p *exportsPlugin) Help(method string) (string, error) {
+	return p.impl.Help(method)
+}
+
+// asDynamicPlugin 将Dispense得到的实例统一适配为 DynamicPlugin
+func asDynamicPlugin(raw interface{}) (DynamicPlugin, error) {
+	switch p := raw.(type) {
+	case DynamicPlugin:
+		return p, nil
+	case dynamic_plugin_shared.DynamicPluginInterface:
+		return &exportsPlugin{impl: p}, nil
+	default:
+		return nil, fmt.Errorf("不支持的插件类型 %T", raw)
+	}
+}
+
+// InvokeContext 带上下文调用插件
+// 插件实现了 ContextPlugin 时直接传入上下文; 否则上下文结束时立即返回, 调用在后台运行到结束
+func InvokeContext(ctx context.Context, plugin DynamicPlugin, method string, args ...interface{}) (interface{}, error) {
+	if p, ok := plugin.(ContextPlugin); ok {
+		return p.InvokeContext(ctx, method, args...)
+	}
+	return dynamic_plugin_shared.AwaitContext(ctx, func() (interface{}, error) {
+		return plugin.Invoke(method, args...)
+	})
+}
+
+// InvokeOptions 带关键字选项调用插件
+// 插件未实现 OptionsPlugin 时只能在没有选项的情况下调用
+func InvokeOptions(ctx context.Context, plugin DynamicPlugin, method string, args []interface{}, options map[string]interface{}) (interface{}, error) {
+	if p, ok := plugin.(OptionsPlugin); ok {
+		return p.InvokeOptions(ctx, method, args, options)
+	}
+	if len(options) > 0 {
+		return nil, NewError(CodeInvalidArgument, "插件不支持关键字选项")
+	}
+	return InvokeContext(ctx, plugin, method, args...)
+}
+
 // CalculatorABI 生成计算器插件的ABI描述
 func CalculatorABI() *PluginABI {
 	return &PluginABI{
 		Name:    "calculator",
 		Version: "1.0.0",
 		Methods: map[string]MethodSpec{
-			"Add":      {Params: []string{"int", "int"}, Returns: "int,error"},
-			"Subtract": {Params: []string{"int", "int"}, Returns: "int,error"},
-			"Multiply": {Params: []string{"int", "int"}, Returns: "int,error"},
-			"Divide":   {Params: []string{"int", "int"}, Returns: "float64,error"},
+			"Add":      {Params: ParamTypes("int", "int"), Returns: "int,error"},
+			"Subtract": {Params: ParamTypes("int", "int"), Returns: "int,error"},
+			"Multiply": {Params: ParamTypes("int", "int"), Returns: "int,error"},
+			"Divide":   {Params: ParamTypes("int", "int"), Returns: "float64,error"},
 		},
 	}
 }
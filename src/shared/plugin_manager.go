@@ -1,12 +1,18 @@
 package shared
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
 
 	"github.com/hashicorp/go-plugin"
 	goplugin "github.com/hashicorp/go-plugin"
@@ -19,145 +25,612 @@ var Handshake = goplugin.HandshakeConfig{
 	MagicCookieValue: "dynamic",
 }
 
-// PluginManager 管理动态加载的插件
+// DynamicProtocols dynamic_plugin_shared 插件支持的传输协议, 由插件端决定使用哪一种
+var DynamicProtocols = []goplugin.Protocol{goplugin.ProtocolNetRPC, goplugin.ProtocolGRPC}
+
+// abiTimeout 加载插件时获取ABI的超时时间
+// 插件实际实现的协议与宿主不一致时, net/rpc 调用插件不存在的方法可能一直不返回
+const abiTimeout = 10 * time.Second
+
+// PluginManager 管理动态加载的插件, 可以被多个goroutine并发使用
+// 插件表由 mu 保护; 每个插件的进程生命周期由插件自身的锁保护,
+// 因此加载或卸载一个插件不会阻塞对其他插件的调用
 type PluginManager struct {
-	Plugins map[string]*goplugin.Client
-	ABIs    map[string]*PluginABI
+	mu       sync.RWMutex
+	plugins  map[string]*managedPlugin
+	policy   RestartPolicy
+	timeout  time.Duration  // 插件和方法都未配置超时时使用
+	limits   ResourceLimits // 插件目录中插件的资源限制
+	security SecurityConfig // 插件可执行文件的校验和连接加密要求
+}
+
+// managedPlugin 一个已加载的插件
+// 调用期间持有读锁, 卸载时持有写锁, 卸载会等待进行中的调用结束
+type managedPlugin struct {
+	mu     sync.RWMutex
+	name   string
+	path   string
+	key    string // 插件端 plugin map 中的键, Dispense 时使用
+	closed bool
+	start  func() (*managedPlugin, error) // 重新启动插件进程, 供监控重启使用
+	stop   chan struct{}                  // 插件卸载时关闭, 通知监控退出
+
+	timeout        time.Duration
+	methodTimeouts map[string]time.Duration
+
+	// stateMu 保护以下字段, 插件重启时会替换进程相关的字段
+	stateMu     sync.Mutex
+	client      *goplugin.Client
+	runner      *sandboxRunner // 设置了资源限制时运行插件进程, 否则为 nil
+	abi         *PluginABI
+	inst        DynamicPlugin // 缓存的插件实例, 避免每次调用都重新 Dispense
+	state       PluginState
+	ready       chan struct{} // 插件恢复运行或重启失败时关闭
+	restarts    int
+	lastErr     error
+	lastRestart time.Time
 }
 
 func NewPluginManager() *PluginManager {
 	return &PluginManager{
-		Plugins: make(map[string]*goplugin.Client),
-		ABIs:    make(map[string]*PluginABI),
+		plugins: make(map[string]*managedPlugin),
+		policy:  DefaultRestartPolicy,
 	}
 }
 
 // LoadFromConfig 从配置文件加载插件
+// 单个插件加载失败不影响其他插件, 所有错误汇总返回
 func (pm *PluginManager) LoadFromConfig(configPath string) error {
-	configFile, err := os.ReadFile(configPath)
+	config, err := ReadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("读取插件配置文件失败: %v", err)
+		return err
 	}
+	return pm.LoadConfig(config)
+}
+
+// LoadConfig 按已读取的配置加载插件, 所有错误由 errors.Join 汇总, 单个插件的加载错误为 *LoadError
+func (pm *PluginManager) LoadConfig(config *Config) error {
+	pm.mu.Lock()
+	pm.timeout = time.Duration(config.Sandbox.Timeout)
+	pm.limits = config.Sandbox.ResourceLimits
+	pm.security = config.Security
+	pm.mu.Unlock()
 
-	var config struct {
-		Plugins []struct {
-			Name      string                 `json:"name"`
-			Path      string                 `json:"path"`
-			Handshake plugin.HandshakeConfig `json:"handshake"`
-		} `json:"plugins"`
+	var errs []error
+	for _, pluginConfig := range config.Plugins {
+		pluginConfig.Limits = config.Sandbox.ResourceLimits.Merge(pluginConfig.Limits)
+		if err := pm.LoadPlugin(pluginConfig); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		return fmt.Errorf("解析插件配置失败: %v", err)
+	if config.PluginDir != "" {
+		if err := pm.LoadFromDir(config.PluginDir); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	for _, pluginConfig := range config.Plugins {
-		client := plugin.NewClient(&plugin.ClientConfig{
-			HandshakeConfig: pluginConfig.Handshake,
-			Plugins:         map[string]plugin.Plugin{"dynamic": &DynamicPluginRPC{}},
-			Cmd:             exec.Command(pluginConfig.Path),
-		})
+// LoadError 单个插件的加载错误, 错误信息与 Err 相同
+// 调用方可以用 errors.As 从 LoadConfig 汇总的错误中找出加载失败的插件
+type LoadError struct {
+	Name string // 插件名, 从插件目录加载时为文件名
+	Path string
+	Err  error
+}
 
-		rpcClient, err := client.Client()
-		if err != nil {
-			log.Printf("连接插件 %s RPC失败: %v", pluginConfig.Name, err)
+func (e *LoadError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// SplitErrors 展开 errors.Join 汇总的错误, 嵌套的汇总错误逐层展开, 返回其中的每一条错误
+// LoadConfig 返回的错误中, 插件目录的错误本身也是汇总错误
+func SplitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, SplitErrors(e)...)
+	}
+	return errs
+}
+
+// LoadPlugin 按配置加载单个插件, 同名插件已加载时替换旧的插件
+// 加载失败时返回 *LoadError
+func (pm *PluginManager) LoadPlugin(pluginConfig PluginConfig) error {
+	pm.mu.RLock()
+	security := pm.security
+	pm.mu.RUnlock()
+
+	p, err := startConfigPlugin(pluginConfig, security)
+	if err != nil {
+		return &LoadError{Name: pluginConfig.Name, Path: pluginConfig.Path, Err: err}
+	}
+	p.timeout = time.Duration(pluginConfig.Timeout)
+	p.methodTimeouts = make(map[string]time.Duration, len(pluginConfig.MethodTimeouts))
+	for method, timeout := range pluginConfig.MethodTimeouts {
+		p.methodTimeouts[method] = time.Duration(timeout)
+	}
+	if err := pm.register(p); err != nil {
+		return &LoadError{Name: pluginConfig.Name, Path: pluginConfig.Path, Err: err}
+	}
+	log.Printf("成功加载插件: %s v%s", p.name, p.currentABI().Version)
+	return nil
+}
+
+// startConfigPlugin 启动 dynamic_plugin_shared 插件, 插件按其报告的名称注册
+// pluginConfig.Name 为空时(插件目录中的插件)接受插件报告的任意名称, 否则两者必须一致; 重启时要求名称不变。
+// 启动前校验ABI描述文件格式和可执行文件, 并从校验过的副本启动, 重启时同样会重新校验;
+// 连接后再将插件报告的ABI与描述文件比较
+func startConfigPlugin(pluginConfig PluginConfig, security SecurityConfig) (*managedPlugin, error) {
+	// 插件报告名称之前, 错误信息、签名校验和cgroup名使用配置的名称或文件名
+	label := pluginConfig.Name
+	if label == "" {
+		label = PluginName(pluginConfig.Path)
+	}
+	if err := checkPluginFile(pluginConfig.Path); err != nil {
+		return nil, fmt.Errorf("插件 %s: %v", label, err)
+	}
+	manifest, err := readPluginManifest(pluginConfig.Path)
+	if err != nil {
+		return nil, fmt.Errorf("插件 %s: %v", label, err)
+	}
+	pinned, err := VerifyPlugin(label, pluginConfig.Path, pluginConfig.Checksum, pluginConfig.Signature, security)
+	if err != nil {
+		return nil, fmt.Errorf("插件 %s 校验失败: %v", label, err)
+	}
+	// 插件进程从校验过的副本启动, 连接建立后进程已经启动, 不再需要副本
+	defer releasePinned(pinned)
+
+	// 未配置握手时使用 dynamic_plugin_shared.Serve 的握手配置, 与插件名无关
+	handshake := pluginConfig.Handshake
+	if handshake.MagicCookieKey == "" {
+		handshake = dynamic_plugin_shared.HandshakeConfig
+	}
+	clientConfig := &plugin.ClientConfig{
+		HandshakeConfig: handshake,
+		Plugins: map[string]plugin.Plugin{
+			dynamic_plugin_shared.PluginKey: &dynamic_plugin_shared.DynamicPlugin{},
+		},
+		AllowedProtocols: DynamicProtocols,
+	}
+	env, err := setTLS(clientConfig, security.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("插件 %s: %v", label, err)
+	}
+	runner, err := setCommand(clientConfig, label, pluginConfig.Path, pinned, pluginConfig.Limits, env)
+	if err != nil {
+		return nil, fmt.Errorf("插件 %s: %v", label, err)
+	}
+	client := plugin.NewClient(clientConfig)
+
+	rpcClient, err := connectClient(client, clientConfig, security.TLS)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("连接插件 %s RPC失败: %v", label, err)
+	}
+
+	raw, err := rpcClient.Dispense(dynamic_plugin_shared.PluginKey)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("获取插件 %s 实例失败: %v", label, err)
+	}
+
+	// 由插件导出的函数表生成ABI, 而不是反射RPC客户端桩
+	plugin, err := asDynamicPlugin(raw)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("插件 %s: %v", label, err)
+	}
+	abi, err := fetchABI(plugin)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("获取插件 %s ABI失败: %v", label, err)
+	}
+	if abi.Name == "" {
+		client.Kill()
+		return nil, fmt.Errorf("插件 %s 没有报告名称", label)
+	}
+	if pluginConfig.Name != "" && abi.Name != pluginConfig.Name {
+		client.Kill()
+		return nil, fmt.Errorf("插件 %s 报告的名称为 %s, 与配置不一致", pluginConfig.Name, abi.Name)
+	}
+
+	if manifest != nil {
+		if err := CompareABI(manifest, abi); err != nil {
+			client.Kill()
+			return nil, fmt.Errorf("插件 %s: %v", abi.Name, err)
+		}
+	}
+
+	pluginConfig.Name = abi.Name
+	return &managedPlugin{
+		name:   abi.Name,
+		path:   pluginConfig.Path,
+		key:    dynamic_plugin_shared.PluginKey,
+		start:  func() (*managedPlugin, error) { return startConfigPlugin(pluginConfig, security) },
+		client: client,
+		runner: runner,
+		abi:    abi,
+		inst:   plugin,
+	}, nil
+}
+
+// fetchABI 在 abiTimeout 内获取插件的ABI
+// 超时后由调用方结束插件进程, 阻塞的RPC调用随连接关闭返回
+func fetchABI(plugin DynamicPlugin) (*PluginABI, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), abiTimeout)
+	defer cancel()
+	result, err := dynamic_plugin_shared.AwaitContext(ctx, func() (interface{}, error) {
+		return plugin.GetABI()
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%v 内未返回ABI, 插件可能没有实现动态插件协议", abiTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PluginABI), nil
+}
+
+// checkPluginFile 确认插件路径存在且不是目录
+func checkPluginFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("读取插件失败: %v", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s必须是一个文件而不是目录", path)
+	}
+	return nil
+}
+
+// LoadFromDir 扫描插件目录, 加载其中所有可执行文件
+// 每个插件按其报告的名称注册, 与文件名无关; 名称已被其他路径的插件注册时拒绝加载。
+// 单个插件加载失败不影响其他插件, 所有错误汇总返回, 其中单个插件的错误为 *LoadError
+func (pm *PluginManager) LoadFromDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("读取插件目录失败: %v", err)
+	}
+
+	pm.mu.RLock()
+	limits, security := pm.limits, pm.security
+	pm.mu.RUnlock()
+
+	var errs []error
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !isExecutable(path) {
 			continue
 		}
 
-		raw, err := rpcClient.Dispense("dynamic")
+		name := PluginName(path)
+		p, err := loadPlugin(path, limits, security)
 		if err != nil {
-			log.Printf("获取插件 %s 实例失败: %v", pluginConfig.Name, err)
+			errs = append(errs, &LoadError{Name: name, Path: path, Err: fmt.Errorf("加载插件 %s 失败: %v", path, err)})
 			continue
 		}
 
-		plugin := raw.(DynamicPlugin)
-		generator := NewABIGenerator()
-		abi, err := generator.GenerateFromInstance(pluginConfig.Name, "1.0.0", plugin)
-		if err != nil {
-			log.Printf("生成插件 %s ABI失败: %v", pluginConfig.Name, err)
+		if err := pm.register(p); err != nil {
+			errs = append(errs, &LoadError{Name: name, Path: path, Err: fmt.Errorf("加载插件 %s 失败: %v", path, err)})
 			continue
 		}
+		log.Printf("成功加载插件: %s v%s (%s)", p.name, p.currentABI().Version, path)
+	}
+
+	return errors.Join(errs...)
+}
+
+// isExecutable 判断路径是否为可执行的普通文件
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// PluginName 插件目录中可执行文件的默认名称, 即去掉 .exe 后缀的文件名
+// 插件启动并报告名称之前, 错误信息和签名校验使用该名称; 注册时使用插件报告的名称
+func PluginName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".exe")
+}
+
+// loadPlugin 启动插件目录中的插件, 与配置文件中的插件走同样的启动流程
+// 插件目录中的插件没有单独的配置, 只能使用签名描述文件中的校验和与签名
+func loadPlugin(path string, limits ResourceLimits, security SecurityConfig) (*managedPlugin, error) {
+	return startConfigPlugin(PluginConfig{Path: path, Limits: limits}, security)
+}
 
-		pm.Plugins[pluginConfig.Name] = client
-		pm.ABIs[pluginConfig.Name] = abi
-		log.Printf("成功加载插件: %s", pluginConfig.Name)
+// register 注册已启动的插件
+// 同一路径的插件重新加载时替换旧插件, 旧插件在进行中的调用结束后关闭; 不同路径的同名插件视为冲突
+func (pm *PluginManager) register(p *managedPlugin) error {
+	pm.mu.Lock()
+	old, ok := pm.plugins[p.name]
+	if ok && old.path != p.path {
+		pm.mu.Unlock()
+		p.client.Kill()
+		return fmt.Errorf("插件名 %s 已被 %s 注册", p.name, old.path)
 	}
+	p.state = PluginRunning
+	p.ready = make(chan struct{})
+	close(p.ready)
+	p.stop = make(chan struct{})
+	pm.plugins[p.name] = p
+	pm.mu.Unlock()
 
+	if old != nil {
+		old.close()
+	}
+	go pm.supervise(p)
 	return nil
 }
 
-func (pm *PluginManager) loadPlugin(path string) (*goplugin.Client, *PluginABI, error) {
-	// 1. 创建插件客户端
-	client := goplugin.NewClient(&goplugin.ClientConfig{
-		HandshakeConfig: Handshake,
-		Plugins:         map[string]goplugin.Plugin{"dynamic": &DynamicPluginRPC{}},
-		Cmd:             exec.Command(path),
-	})
+// get 查找已加载的插件
+func (pm *PluginManager) get(pluginName string) (*managedPlugin, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	p, ok := pm.plugins[pluginName]
+	return p, ok
+}
 
-	// 2. 连接RPC客户端
-	rpcClient, err := client.Client()
-	if err != nil {
-		return nil, nil, fmt.Errorf("RPC连接失败: %v", err)
+// ABI 返回已加载插件的ABI描述
+func (pm *PluginManager) ABI(pluginName string) (*PluginABI, bool) {
+	p, ok := pm.get(pluginName)
+	if !ok {
+		return nil, false
 	}
+	return p.currentABI(), true
+}
 
-	// 3. 获取插件实例
-	raw, err := rpcClient.Dispense("dynamic")
-	if err != nil {
-		return nil, nil, fmt.Errorf("获取插件实例失败: %v", err)
+// ABIs 返回所有已加载插件的ABI描述
+func (pm *PluginManager) ABIs() map[string]*PluginABI {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	abis := make(map[string]*PluginABI, len(pm.plugins))
+	for name, p := range pm.plugins {
+		abis[name] = p.currentABI()
 	}
+	return abis
+}
+
+// Names 返回所有已加载插件的名称, 按字母顺序排列
+func (pm *PluginManager) Names() []string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	names := make([]string, 0, len(pm.plugins))
+	for name := range pm.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	// 4. 生成ABI描述
-	dynamicPlugin := raw.(DynamicPlugin)
+// Invoke 动态调用插件方法, 使用配置中的超时时间
+func (pm *PluginManager) Invoke(pluginName, method string, args ...interface{}) (interface{}, error) {
+	return pm.InvokeContext(context.Background(), pluginName, method, args...)
+}
+
+// InvokeContext 带上下文动态调用插件方法
+// 配置了超时时间时在 ctx 上叠加超时, 截止时间随请求传给插件进程;
+// 插件进程崩溃时调用立即失败, 方法声明为幂等时等待插件重启后重试一次
+func (pm *PluginManager) InvokeContext(ctx context.Context, pluginName, method string, args ...interface{}) (interface{}, error) {
+	return pm.InvokeOptions(ctx, pluginName, method, args, nil)
+}
 
-	// 检查缓存
-	if cachedABI, ok := pm.ABIs[filepath.Base(path)]; ok {
-		return client, cachedABI, nil
+// InvokeOptions 与 InvokeContext 相同, 同时按名称传入关键字选项
+// 选项由插件按方法声明检查, 调用方可以先用 CoerceOptions 按ABI转换命令行输入
+// 返回的错误带有错误码, 可以用 CodeOf 或 errors.Is(err, CodeNotFound) 等判断
+func (pm *PluginManager) InvokeOptions(ctx context.Context, pluginName, method string, args []interface{}, options map[string]interface{}) (interface{}, error) {
+	p, ok := pm.get(pluginName)
+	if !ok {
+		return nil, NewError(CodeNotFound, "插件 %s 未加载", pluginName)
 	}
 
-	// 使用ABI生成器
-	generator := NewABIGenerator()
-	abi, err := generator.GenerateFromInstance(
-		filepath.Base(path),
-		"1.0.0",
-		dynamicPlugin,
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("生成ABI失败: %v", err)
+	if timeout := p.timeoutFor(method, pm.defaultTimeout()); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	// 存入缓存
-	pm.ABIs[filepath.Base(path)] = abi
+	result, err := p.invoke(ctx, method, args, options)
+	if err == nil || !errors.Is(err, errPluginDown) {
+		return result, err
+	}
+	if spec, ok := p.currentABI().Methods[method]; !ok || !spec.Idempotent {
+		return nil, err
+	}
 
-	return client, abi, nil
+	log.Printf("插件 %s 不可用, 等待重启后重试幂等方法 %s", pluginName, method)
+	if err := p.waitReady(ctx, pm.restartPolicy().RetryTimeout); err != nil {
+		return nil, err
+	}
+	return p.invoke(ctx, method, args, options)
 }
 
-// Invoke 动态调用插件方法
-func (pm *PluginManager) Invoke(pluginName, method string, args ...interface{}) (interface{}, error) {
-	client, ok := pm.Plugins[pluginName]
+// Help 返回方法的帮助信息
+// 插件实现了 HelpPlugin 时向插件进程查询, 否则使用ABI中的说明; 超时时间与调用该方法相同
+func (pm *PluginManager) Help(ctx context.Context, pluginName, method string) (string, error) {
+	p, ok := pm.get(pluginName)
 	if !ok {
-		return nil, fmt.Errorf("插件 %s 未加载", pluginName)
+		return "", NewError(CodeNotFound, "插件 %s 未加载", pluginName)
+	}
+
+	if timeout := p.timeoutFor(method, pm.defaultTimeout()); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return p.help(ctx, method)
+}
+
+func (pm *PluginManager) defaultTimeout() time.Duration {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.timeout
+}
+
+// timeoutFor 返回方法的超时时间, 依次使用方法、插件和全局的配置
+func (p *managedPlugin) timeoutFor(method string, fallback time.Duration) time.Duration {
+	if timeout, ok := p.methodTimeouts[method]; ok {
+		return timeout
+	}
+	if p.timeout > 0 {
+		return p.timeout
+	}
+	return fallback
+}
+
+func (p *managedPlugin) invoke(ctx context.Context, method string, args []interface{}, options map[string]interface{}) (interface{}, error) {
+	// 调用期间持有读锁, 防止插件在调用中被卸载
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return nil, NewError(CodeNotFound, "插件 %s 未加载", p.name)
 	}
 
-	// 获取RPC客户端
-	rpcClient, err := client.Client()
+	plugin, err := p.instance()
 	if err != nil {
 		return nil, err
 	}
+	result, err := InvokeOptions(ctx, plugin, method, args, options)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, WrapError(CodeTimeout, fmt.Errorf("调用 %s.%s 超时: %w", p.name, method, err))
+	}
+	if err != nil && p.lost(err) {
+		cause := p.lostCause(err)
+		p.markDown(cause)
+		return nil, WrapError(CodeUnavailable, fmt.Errorf("%w: 插件 %s 在调用 %s 时退出: %v", errPluginDown, p.name, method, cause))
+	}
+	return result, err
+}
+
+func (p *managedPlugin) help(ctx context.Context, method string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return "", NewError(CodeNotFound, "插件 %s 未加载", p.name)
+	}
 
-	// 动态调用
-	raw, err := rpcClient.Dispense("dynamic")
+	plugin, err := p.instance()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	helpPlugin, ok := plugin.(HelpPlugin)
+	if !ok {
+		spec, ok := p.currentABI().Methods[method]
+		if !ok {
+			return "", NewError(CodeNotFound, "方法 %s.%s 不存在", p.name, method)
+		}
+		return spec.Help, nil
+	}
+
+	result, err := dynamic_plugin_shared.AwaitContext(ctx, func() (interface{}, error) {
+		return helpPlugin.Help(method)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", WrapError(CodeTimeout, fmt.Errorf("查询 %s.%s 的帮助信息超时: %w", p.name, method, err))
+	}
+	if err != nil {
+		return "", err
 	}
+	return result.(string), nil
+}
+
+// Unload 卸载单个插件, 等待该插件进行中的调用结束后关闭插件进程
+func (pm *PluginManager) Unload(pluginName string) error {
+	pm.mu.Lock()
+	p, ok := pm.plugins[pluginName]
+	delete(pm.plugins, pluginName)
+	pm.mu.Unlock()
 
-	// 类型断言并调用
-	plugin := raw.(DynamicPlugin)
-	return plugin.Invoke(method, args...)
+	if !ok {
+		return fmt.Errorf("插件 %s 未加载", pluginName)
+	}
+	p.close()
+	return nil
 }
 
 // UnloadAll 卸载所有插件
 func (pm *PluginManager) UnloadAll() {
-	for _, client := range pm.Plugins {
-		client.Kill()
+	pm.mu.Lock()
+	plugins := pm.plugins
+	pm.plugins = make(map[string]*managedPlugin)
+	pm.mu.Unlock()
+
+	for _, p := range plugins {
+		p.close()
 	}
 }
+
+// instance 返回缓存的插件实例
+// 缓存在插件进程退出后失效; 缓存为空时重新获取RPC客户端并 Dispense
+func (p *managedPlugin) instance() (DynamicPlugin, error) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	switch p.state {
+	case PluginRestarting:
+		return nil, WrapError(CodeUnavailable, fmt.Errorf("%w: 插件 %s 正在重启", errPluginDown, p.name))
+	case PluginFailed:
+		return nil, WrapError(CodeUnavailable, fmt.Errorf("%w: 插件 %s 重启失败: %v", errPluginDown, p.name, p.lastErr))
+	}
+	if p.client.Exited() {
+		cause := p.exitCause()
+		p.markDownLocked(cause)
+		return nil, WrapError(CodeUnavailable, fmt.Errorf("%w: 插件 %s %v", errPluginDown, p.name, cause))
+	}
+	if p.inst != nil {
+		return p.inst, nil
+	}
+
+	rpcClient, err := p.client.Client()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rpcClient.Dispense(p.key)
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := asDynamicPlugin(raw)
+	if err != nil {
+		return nil, err
+	}
+	p.inst = plugin
+	return plugin, nil
+}
+
+// close 等待进行中的调用结束后关闭插件进程
+func (p *managedPlugin) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.stop)
+
+	p.stateMu.Lock()
+	client := p.client
+	p.inst = nil
+	p.stateMu.Unlock()
+	client.Kill()
+}
+
+// currentABI 返回插件当前进程报告的ABI描述
+func (p *managedPlugin) currentABI() *PluginABI {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	return p.abi
+}
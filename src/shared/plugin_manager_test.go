@@ -0,0 +1,276 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// buildPlugin 将 src/plugins 下的插件编译到 dir 中, 返回可执行文件路径
+func buildPlugin(t *testing.T, dir, name string) string {
+	t.Helper()
+	return buildPackage(t, filepath.Join(dir, name), "go-plugin-demo/src/plugins/"+name)
+}
+
+// buildPackage 将插件包 pkg 编译为 path
+func buildPackage(t *testing.T, path, pkg string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("跳过需要编译插件的测试")
+	}
+	cmd := exec.Command("go", "build", "-o", path, pkg)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("编译插件 %s 失败: %v\n%s", pkg, err, output)
+	}
+	return path
+}
+
+func TestLoadFromDir(t *testing.T) {
+	dir := t.TempDir()
+	buildPlugin(t, dir, "date_utils")
+	broken := filepath.Join(dir, "broken")
+	if err := os.WriteFile(broken, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("不可执行的文件会被忽略"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pm := NewPluginManager()
+	defer pm.UnloadAll()
+	err := pm.LoadFromDir(dir)
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("LoadFromDir 应返回 broken 的 *LoadError, 实际为 %v", err)
+	}
+	if loadErr.Name != "broken" || loadErr.Path != broken {
+		t.Errorf("LoadError = {%q, %q}, 期望 {broken, %s}", loadErr.Name, loadErr.Path, broken)
+	}
+
+	if names := fmt.Sprint(pm.Names()); names != "[date_utils]" {
+		t.Fatalf("已加载插件 %s, 期望 [date_utils]", names)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, err := pm.Invoke("date_utils", "Between", start, start.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("调用 date_utils.Between 失败: %v", err)
+	}
+	if fmt.Sprint(result) != "3" {
+		t.Errorf("date_utils.Between = %v, 期望 3", result)
+	}
+}
+
+// 插件目录中的插件按插件报告的名称注册, 与文件名无关; 报告同一名称的第二个插件被拒绝
+func TestLoadFromDirReportedName(t *testing.T) {
+	dir := t.TempDir()
+	built := buildPlugin(t, t.TempDir(), "date_utils")
+	data, err := os.ReadFile(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dates", "dates2"} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pm := NewPluginManager()
+	defer pm.UnloadAll()
+	err = pm.LoadFromDir(dir)
+	if names := fmt.Sprint(pm.Names()); names != "[date_utils]" {
+		t.Fatalf("已加载插件 %s, 期望按报告的名称注册为 [date_utils]", names)
+	}
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || loadErr.Path != filepath.Join(dir, "dates2") ||
+		!strings.Contains(err.Error(), "已被 "+filepath.Join(dir, "dates")+" 注册") {
+		t.Errorf("报告同一名称的第二个插件应被拒绝, 实际为 %v", err)
+	}
+	if status, ok := pm.Status("date_utils"); !ok || status.Path != filepath.Join(dir, "dates") {
+		t.Errorf("date_utils 的状态为 %+v, 期望路径为 dates", status)
+	}
+
+	// 配置中的名称必须与插件报告的名称一致
+	err = pm.LoadPlugin(PluginConfig{Name: "calendar", Path: built})
+	if err == nil || !strings.Contains(err.Error(), "报告的名称为 date_utils") {
+		t.Errorf("配置的名称与插件报告的不一致时应返回错误, 实际为 %v", err)
+	}
+}
+
+// 描述文件格式错误时插件进程不会启动; 格式正确但与插件报告的ABI不一致时加载失败
+func TestLoadPluginManifest(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "started")
+	script := filepath.Join(dir, "date_utils")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntouch "+marker+"\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script+ABIManifestSuffix, []byte(`{"name":"date_utils"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pm := NewPluginManager()
+	defer pm.UnloadAll()
+	err := pm.LoadPlugin(PluginConfig{Name: "date_utils", Path: script})
+	if err == nil || !strings.Contains(err.Error(), "ABI描述文件") {
+		t.Errorf("描述文件无效时应返回描述文件的错误, 实际为 %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("描述文件无效时不应启动插件进程")
+	}
+
+	path := buildPlugin(t, t.TempDir(), "date_utils")
+	abi, err := NewABIGenerator().GenerateFromPackage("date_utils", "", "go-plugin-demo/src/plugins/date_utils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abi.Version = "0.9.0"
+	if err := WriteABIManifest(path+ABIManifestSuffix, abi); err != nil {
+		t.Fatal(err)
+	}
+	err = pm.LoadPlugin(PluginConfig{Name: "date_utils", Path: path})
+	if err == nil || !strings.Contains(err.Error(), `版本 "0.9.0" != "1.0.0"`) {
+		t.Errorf("描述文件与插件不一致时应返回版本差异, 实际为 %v", err)
+	}
+	if len(pm.Names()) != 0 {
+		t.Errorf("ABI不一致的插件不应被注册, 已注册 %v", pm.Names())
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+	wrapped := fmt.Errorf("加载失败: %w", errors.Join(a, b))
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{"nil", nil, nil},
+		{"单个错误", a, []error{a}},
+		{"汇总错误", errors.Join(a, b), []error{a, b}},
+		{"嵌套汇总", errors.Join(a, errors.Join(b, c)), []error{a, b, c}},
+		{"包装的汇总错误不展开", wrapped, []error{wrapped}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitErrors(tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitErrors = %v, 期望 %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("第 %d 个错误为 %v, 期望 %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// 卸载一个插件时等待它进行中的调用结束, 其他插件的并发调用不受影响
+func TestUnloadDoesNotBlockOtherPlugins(t *testing.T) {
+	pm, _ := loadCrashPlugin(t)
+	if err := pm.LoadPlugin(PluginConfig{Name: "date_utils", Path: buildPlugin(t, t.TempDir(), "date_utils")}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocked := make(chan error, 1)
+	go func() {
+		_, err := pm.InvokeContext(ctx, "crash", "Block")
+		blocked <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	unloaded := make(chan error, 1)
+	go func() { unloaded <- pm.Unload("crash") }()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(days int) {
+			defer wg.Done()
+			result, err := pm.Invoke("date_utils", "Between", start, start.AddDate(0, 0, days))
+			if err == nil && fmt.Sprint(result) != fmt.Sprint(days) {
+				err = fmt.Errorf("Between 返回 %v, 期望 %d", result, days)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("卸载 crash 期间调用 date_utils 失败: %v", err)
+		}
+	}
+
+	select {
+	case err := <-unloaded:
+		t.Fatalf("Unload 应等待进行中的调用结束, 实际提前返回 %v", err)
+	default:
+	}
+	if _, ok := pm.Status("crash"); ok {
+		t.Error("Unload 开始后 crash 不应再能被查到")
+	}
+
+	cancel()
+	select {
+	case err := <-unloaded:
+		if err != nil {
+			t.Errorf("Unload(crash) 返回 %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("进行中的调用结束后 Unload 没有返回")
+	}
+	if err := <-blocked; !errors.Is(err, context.Canceled) {
+		t.Errorf("被取消的调用返回 %v, 期望 context.Canceled", err)
+	}
+	if names := fmt.Sprint(pm.Names()); names != "[date_utils]" {
+		t.Errorf("已加载插件 %s, 期望 [date_utils]", names)
+	}
+}
+
+// cachedInstance 返回插件当前缓存的实例
+func cachedInstance(pm *PluginManager, name string) DynamicPlugin {
+	p, _ := pm.get(name)
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	return p.inst
+}
+
+// 插件实例只 Dispense 一次; 进程退出后缓存失效, 重启后的调用重新 Dispense
+func TestInstanceRedispensedAfterExit(t *testing.T) {
+	pm, _ := loadCrashPlugin(t)
+	pid := pluginPid(t, pm)
+	first := cachedInstance(pm, "crash")
+	if first == nil {
+		t.Fatal("调用后应缓存插件实例")
+	}
+	if next := pluginPid(t, pm); next != pid || cachedInstance(pm, "crash") != first {
+		t.Error("进程未退出时应复用缓存的插件实例")
+	}
+
+	killProcess(t, pid)
+	waitState(t, pm, PluginRestarting)
+	if cachedInstance(pm, "crash") != nil {
+		t.Error("进程退出后缓存的插件实例应失效")
+	}
+	waitState(t, pm, PluginRunning)
+
+	if next := pluginPid(t, pm); next == pid {
+		t.Errorf("重启后的调用仍到达已结束的进程 %d", pid)
+	}
+	if second := cachedInstance(pm, "crash"); second == nil || second == first {
+		t.Error("重启后应重新 Dispense 并缓存新的插件实例")
+	}
+}
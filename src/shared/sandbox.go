@@ -0,0 +1,223 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/go-plugin/runner"
+)
+
+// setCommand 设置插件进程的启动方式, env 为额外传给插件进程的环境变量
+// pinned 不为空时从 VerifyPlugin 返回的副本启动, 日志中仍显示 path;
+// 没有资源限制和副本时直接使用 Cmd; 否则由 sandboxRunner 启动进程, 进程在 exec 插件之前已受到限制
+func setCommand(config *goplugin.ClientConfig, name, path string, pinned *os.File, limits ResourceLimits, env []string) (*sandboxRunner, error) {
+	if limits.IsZero() && pinned == nil {
+		config.Cmd = exec.Command(path)
+		config.Cmd.Env = env
+		return nil, nil
+	}
+
+	r := &sandboxRunner{name: name, path: path, pinned: pinned, limits: limits, env: env}
+	config.RunnerFunc = r.init
+	return r, nil
+}
+
+// sandboxRunner 在资源限制下运行插件进程的 runner.Runner
+// 进程退出后记录是否因超出资源限制被结束
+type sandboxRunner struct {
+	name   string
+	path   string
+	pinned *os.File // 校验过的可执行文件副本, 为空时直接启动 path
+	limits ResourceLimits
+	env    []string
+
+	logger  hclog.Logger
+	cmd     *exec.Cmd
+	sandbox *sandbox
+	stdout  io.ReadCloser
+	stderr  *limitWatcher
+	pid     int
+
+	mu         sync.Mutex
+	exitReason string
+}
+
+var _ runner.Runner = (*sandboxRunner)(nil)
+
+// init 实现 ClientConfig.RunnerFunc, spec 中带有 go-plugin 准备好的环境变量
+func (r *sandboxRunner) init(logger hclog.Logger, spec *exec.Cmd, _ string) (runner.Runner, error) {
+	sb, err := newSandbox(r.name, r.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(r.path)
+	if r.pinned != nil {
+		cmd.Path, cmd.ExtraFiles = pinnedCommand(r.pinned)
+	}
+	cmd.Env = append(append([]string(nil), r.env...), spec.Env...)
+	cmd.Stdin = spec.Stdin
+	if err := sb.prepare(cmd); err != nil {
+		sb.cleanup()
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		sb.cleanup()
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		sb.cleanup()
+		return nil, err
+	}
+
+	r.logger = logger
+	r.cmd = cmd
+	r.sandbox = sb
+	r.stdout = stdout
+	r.stderr = &limitWatcher{ReadCloser: stderr}
+	return r, nil
+}
+
+func (r *sandboxRunner) Start(_ context.Context) error {
+	r.logger.Debug("starting plugin", "path", r.path, "limits", fmt.Sprintf("%+v", r.limits))
+	err := r.cmd.Start()
+	r.sandbox.started()
+	if err != nil {
+		r.sandbox.cleanup()
+		return err
+	}
+	r.pid = r.cmd.Process.Pid
+	return nil
+}
+
+func (r *sandboxRunner) Wait(_ context.Context) error {
+	err := r.cmd.Wait()
+
+	reason := r.sandbox.exitReason(r.cmd.ProcessState)
+	r.sandbox.cleanup()
+
+	r.mu.Lock()
+	r.exitReason = reason
+	r.mu.Unlock()
+	return err
+}
+
+func (r *sandboxRunner) Kill(_ context.Context) error {
+	if r.cmd.Process == nil {
+		return nil
+	}
+	if err := r.cmd.Process.Kill(); !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// ExitReason 插件进程因超出资源限制被结束时返回原因, 否则返回空字符串
+func (r *sandboxRunner) ExitReason() string {
+	r.mu.Lock()
+	reason := r.exitReason
+	r.mu.Unlock()
+	if reason != "" || r.stderr == nil {
+		return reason
+	}
+	// 标准错误输出由 go-plugin 异步读取, 进程退出后再检查其中的错误信息
+	return r.stderr.reason(r.limits)
+}
+
+func (r *sandboxRunner) Stdout() io.ReadCloser { return r.stdout }
+func (r *sandboxRunner) Stderr() io.ReadCloser { return r.stderr }
+func (r *sandboxRunner) Name() string          { return r.path }
+func (r *sandboxRunner) ID() string            { return strconv.Itoa(r.pid) }
+
+func (r *sandboxRunner) Diagnose(_ context.Context) string {
+	return fmt.Sprintf("插件 %s 启动失败: 可能不是当前平台的可执行文件、握手配置不一致或资源限制过低 (%+v)", r.path, r.limits)
+}
+
+func (r *sandboxRunner) PluginToHost(pluginNet, pluginAddr string) (string, string, error) {
+	return pluginNet, pluginAddr, nil
+}
+
+func (r *sandboxRunner) HostToPlugin(hostNet, hostAddr string) (string, string, error) {
+	return hostNet, hostAddr, nil
+}
+
+// limitMessages Go运行时在资源耗尽时输出的错误信息
+var limitMessages = [...]struct {
+	marker []byte
+	reason func(ResourceLimits) string
+}{
+	{[]byte("out of memory"), func(l ResourceLimits) string {
+		if l.MemoryLimit == 0 {
+			return ""
+		}
+		return fmt.Sprintf("内存超过限制 %s", l.MemoryLimit)
+	}},
+	{[]byte("failed to create new OS thread"), func(l ResourceLimits) string {
+		if l.MaxProcesses == 0 {
+			return ""
+		}
+		return fmt.Sprintf("进程数超过限制 %d", l.MaxProcesses)
+	}},
+	{[]byte("too many open files"), func(l ResourceLimits) string {
+		if l.MaxOpenFiles == 0 {
+			return ""
+		}
+		return fmt.Sprintf("打开文件数超过限制 %d", l.MaxOpenFiles)
+	}},
+}
+
+// limitWatcher 转发插件的标准错误输出, 同时记录资源耗尽的错误信息
+type limitWatcher struct {
+	io.ReadCloser
+
+	mu   sync.Mutex
+	tail []byte // 上次读取的末尾, 避免错误信息跨两次读取时漏掉
+	seen [len(limitMessages)]bool
+}
+
+func (w *limitWatcher) Read(p []byte) (int, error) {
+	n, err := w.ReadCloser.Read(p)
+	if n > 0 {
+		w.mu.Lock()
+		buf := append(w.tail, p[:n]...)
+		for i, m := range limitMessages {
+			if bytes.Contains(buf, m.marker) {
+				w.seen[i] = true
+			}
+		}
+		keep := 64
+		if len(buf) < keep {
+			keep = len(buf)
+		}
+		w.tail = append([]byte(nil), buf[len(buf)-keep:]...)
+		w.mu.Unlock()
+	}
+	return n, err
+}
+
+// reason 返回第一个与已设置的限制相关的资源耗尽原因
+func (w *limitWatcher) reason(limits ResourceLimits) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, seen := range w.seen {
+		if !seen {
+			continue
+		}
+		if reason := limitMessages[i].reason(limits); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,259 @@
+//go:build linux
+
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandbox 插件进程的资源限制
+// 配置了 cgroup_parent 时, 内存和进程数由其下为插件创建的子cgroup限制, 其余限制使用 rlimit
+type sandbox struct {
+	limits    ResourceLimits
+	rlimits   []string // 由 execWithRlimits 设置的 rlimit, 格式为 "名称=值"
+	cgroupDir string
+	cgroupFD  *os.File
+}
+
+func newSandbox(name string, limits ResourceLimits) (*sandbox, error) {
+	s := &sandbox{limits: limits}
+	if limits.CgroupParent != "" && (limits.MemoryLimit > 0 || limits.MaxProcesses > 0) {
+		dir, err := newCgroup(limits.CgroupParent, name, limits)
+		if err != nil {
+			return nil, fmt.Errorf("插件 %s 无法使用cgroup %s: %v", name, limits.CgroupParent, err)
+		}
+		s.cgroupDir = dir
+	}
+
+	if limits.MemoryLimit > 0 && s.cgroupDir == "" {
+		// Go运行时会预留大量不可写的地址空间, RLIMIT_AS 会让插件无法启动;
+		// RLIMIT_DATA 只统计可写的私有映射, 与堆内存用量接近
+		s.rlimits = append(s.rlimits, fmt.Sprintf("data=%d", uint64(limits.MemoryLimit)))
+	}
+	if limits.CPUTime > 0 {
+		// 软硬限制相同, 到达限制时内核直接发送 SIGKILL
+		seconds := uint64((time.Duration(limits.CPUTime) + time.Second - 1) / time.Second)
+		s.rlimits = append(s.rlimits, fmt.Sprintf("cpu=%d", seconds))
+	}
+	if limits.MaxOpenFiles > 0 {
+		s.rlimits = append(s.rlimits, fmt.Sprintf("nofile=%d", limits.MaxOpenFiles))
+	}
+	if limits.MaxProcesses > 0 && s.cgroupDir == "" {
+		// RLIMIT_NPROC 统计的是同一用户的全部进程, 不只是插件自身的进程
+		s.rlimits = append(s.rlimits, fmt.Sprintf("nproc=%d", limits.MaxProcesses))
+	}
+	return s, nil
+}
+
+// prepare 在进程创建时将其放入cgroup; 需要 rlimit 时改为经由宿主自身启动插件,
+// 由 execWithRlimits 在 exec 插件之前设置 rlimit, 插件从启动起就受到限制
+func (s *sandbox) prepare(cmd *exec.Cmd) error {
+	if len(s.rlimits) > 0 {
+		if !sandboxExecReady.Load() {
+			return fmt.Errorf("宿主程序没有在 main 中调用 shared.RunSandboxExec, 无法为插件设置 rlimit")
+		}
+		cmd.Env = append(cmd.Env, sandboxExecEnv+"="+cmd.Path, sandboxRlimitsEnv+"="+strings.Join(s.rlimits, ","))
+		cmd.Path = "/proc/self/exe"
+	}
+	if s.cgroupDir == "" {
+		return nil
+	}
+	fd, err := os.Open(s.cgroupDir)
+	if err != nil {
+		return fmt.Errorf("打开cgroup失败: %v", err)
+	}
+	s.cgroupFD = fd
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: int(fd.Fd())}
+	return nil
+}
+
+// started 进程创建后不再需要cgroup的文件描述符
+func (s *sandbox) started() {
+	if s.cgroupFD != nil {
+		s.cgroupFD.Close()
+		s.cgroupFD = nil
+	}
+}
+
+// sandboxExecEnv 和 sandboxRlimitsEnv 经由宿主自身启动插件时, 传递插件路径和需要设置的 rlimit
+const (
+	sandboxExecEnv    = "GO_PLUGIN_DEMO_SANDBOX_EXEC"
+	sandboxRlimitsEnv = "GO_PLUGIN_DEMO_SANDBOX_RLIMITS"
+)
+
+// rlimitResources rlimit 名称与资源的对应关系
+var rlimitResources = map[string]int{
+	"data":   unix.RLIMIT_DATA,
+	"cpu":    unix.RLIMIT_CPU,
+	"nofile": unix.RLIMIT_NOFILE,
+	"nproc":  unix.RLIMIT_NPROC,
+}
+
+// sandboxExecReady 宿主程序已调用 RunSandboxExec, 可以经由宿主自身启动插件
+var sandboxExecReady atomic.Bool
+
+// RunSandboxExec 需要 rlimit 的插件经由宿主自身启动, 加载插件的程序应在 main 开头调用
+// 以 sandboxExecEnv 启动时只设置 rlimit 并 exec 插件, 不会返回; 否则立即返回, 继续运行宿主的 main
+func RunSandboxExec() {
+	if path, ok := os.LookupEnv(sandboxExecEnv); ok {
+		execWithRlimits(path, os.Getenv(sandboxRlimitsEnv))
+	}
+	sandboxExecReady.Store(true)
+}
+
+// execWithRlimits 设置 rlimit 后以当前的参数和环境变量 exec 插件, 不会返回
+// rlimit 和 cgroup 都随 exec 保留; 失败时错误输出到标准错误, 由宿主记录在插件日志中
+func execWithRlimits(path, rlimits string) {
+	os.Unsetenv(sandboxExecEnv)
+	os.Unsetenv(sandboxRlimitsEnv)
+	fail := func(err error) {
+		fmt.Fprintf(os.Stderr, "启动插件 %s 失败: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	for _, item := range strings.Split(rlimits, ",") {
+		name, value, _ := strings.Cut(item, "=")
+		resource, ok := rlimitResources[name]
+		if !ok {
+			fail(fmt.Errorf("未知的资源限制 %q", item))
+		}
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fail(fmt.Errorf("无效的资源限制 %q", item))
+		}
+		// 使用 syscall.Setrlimit, exec 时Go运行时不会再恢复启动时的 RLIMIT_NOFILE
+		if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: n, Max: n}); err != nil {
+			fail(fmt.Errorf("设置 RLIMIT_%s 失败: %v", strings.ToUpper(name), err))
+		}
+	}
+	fail(syscall.Exec(path, os.Args, os.Environ()))
+}
+
+// exitReason 根据退出状态和cgroup事件判断进程是否因超出资源限制被结束
+func (s *sandbox) exitReason(state *os.ProcessState) string {
+	if state == nil {
+		return ""
+	}
+	if s.limits.MemoryLimit > 0 && s.cgroupEvent("memory.events", "oom_kill") > 0 {
+		return fmt.Sprintf("内存超过限制 %s", s.limits.MemoryLimit)
+	}
+
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	cpu := state.UserTime() + state.SystemTime()
+	limit := time.Duration(s.limits.CPUTime)
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return fmt.Sprintf("CPU时间超过限制 %s", limit)
+	case syscall.SIGKILL:
+		// 内核按时钟节拍统计CPU时间, 留出一点误差
+		if limit > 0 && cpu+100*time.Millisecond >= limit {
+			return fmt.Sprintf("CPU时间超过限制 %s", limit)
+		}
+	}
+	return ""
+}
+
+// cleanup 结束cgroup中残留的进程并删除cgroup
+func (s *sandbox) cleanup() {
+	s.started()
+	if s.cgroupDir == "" {
+		return
+	}
+	os.WriteFile(filepath.Join(s.cgroupDir, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < 10; i++ {
+		if err := os.Remove(s.cgroupDir); err == nil || os.IsNotExist(err) {
+			s.cgroupDir = ""
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Printf("删除cgroup %s 失败", s.cgroupDir)
+	s.cgroupDir = ""
+}
+
+// cgroupEvent 读取cgroup事件文件中的计数
+func (s *sandbox) cgroupEvent(file, key string) int {
+	if s.cgroupDir == "" {
+		return 0
+	}
+	data, err := os.ReadFile(filepath.Join(s.cgroupDir, file))
+	if err != nil {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			n, _ := strconv.Atoi(fields[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// newCgroup 在委派给宿主的 parent 下为插件创建子cgroup并写入限制
+// 宿主不会移动任何进程, 也不会修改 parent 的 cgroup.subtree_control;
+// parent 需要已委派给运行宿主的用户, 并已为子cgroup启用所需的 memory 和 pids 控制器
+func newCgroup(parent, name string, limits ResourceLimits) (string, error) {
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("%s 不是 cgroup v2 目录", parent)
+	}
+	enabled, err := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return "", fmt.Errorf("读取cgroup控制器失败: %v", err)
+	}
+	var controllers []string
+	if limits.MemoryLimit > 0 {
+		controllers = append(controllers, "memory")
+	}
+	if limits.MaxProcesses > 0 {
+		controllers = append(controllers, "pids")
+	}
+	for _, controller := range controllers {
+		if !containsString(strings.Fields(string(enabled)), controller) {
+			return "", fmt.Errorf("没有为子cgroup启用 %s 控制器, 需要在 cgroup.subtree_control 中启用", controller)
+		}
+	}
+
+	dir, err := os.MkdirTemp(parent, "plugin-"+name+"-")
+	if err != nil {
+		return "", fmt.Errorf("创建cgroup失败: %v", err)
+	}
+	fail := func(format string, err error) (string, error) {
+		os.Remove(dir)
+		return "", fmt.Errorf(format, err)
+	}
+
+	write := func(file, value string) error {
+		return os.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+	}
+	if limits.MemoryLimit > 0 {
+		if err := write("memory.max", strconv.FormatUint(uint64(limits.MemoryLimit), 10)); err != nil {
+			return fail("设置 memory.max 失败: %v", err)
+		}
+		// 不允许用交换分区绕过内存限制, 没有交换分区时该文件不存在
+		write("memory.swap.max", "0")
+	}
+	if limits.MaxProcesses > 0 {
+		if err := write("pids.max", strconv.FormatUint(limits.MaxProcesses, 10)); err != nil {
+			return fail("设置 pids.max 失败: %v", err)
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,98 @@
+//go:build linux
+
+package shared
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandboxRlimitsBeforeExec(t *testing.T) {
+	path := buildPlugin(t, t.TempDir(), "date_utils")
+
+	pm := NewPluginManager()
+	defer pm.UnloadAll()
+	err := pm.LoadPlugin(PluginConfig{
+		Name:   "date_utils",
+		Path:   path,
+		Limits: ResourceLimits{CPUTime: Duration(time.Minute), MaxOpenFiles: 64},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := pm.get("date_utils")
+	p.stateMu.Lock()
+	pid := p.runner.pid
+	p.stateMu.Unlock()
+
+	// 经由宿主自身设置 rlimit 后 exec 的应是插件本身
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exe != path {
+		t.Errorf("插件进程的可执行文件为 %s, 期望 %s", exe, path)
+	}
+	if _, ok := os.LookupEnv(sandboxExecEnv); ok {
+		t.Errorf("测试进程不应带有 %s", sandboxExecEnv)
+	}
+
+	limits, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`Max cpu time\s+60\s+60\s+seconds`,
+		`Max open files\s+64\s+64\s+files`,
+	} {
+		if !regexp.MustCompile(want).Match(limits) {
+			t.Errorf("插件进程的 rlimit 不符合 %q:\n%s", want, limits)
+		}
+	}
+}
+
+// 用普通目录模拟委派给宿主的cgroup, 宿主只在其下创建子cgroup, 不修改父cgroup
+func TestNewCgroupDelegatedParent(t *testing.T) {
+	parent := t.TempDir()
+	subtree := filepath.Join(parent, "cgroup.subtree_control")
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.controllers"), []byte("cpu memory pids\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(subtree, []byte("pids\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newCgroup(parent, "date_utils", ResourceLimits{MemoryLimit: 64 << 20, MaxProcesses: 8})
+	if err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("父cgroup没有启用 memory 控制器时应返回错误, 实际为 %v", err)
+	}
+	if data, _ := os.ReadFile(subtree); string(data) != "pids\n" {
+		t.Errorf("父cgroup的 subtree_control 被修改为 %q", data)
+	}
+
+	dir, err := newCgroup(parent, "date_utils", ResourceLimits{MaxProcesses: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(dir) != parent || !strings.HasPrefix(filepath.Base(dir), "plugin-date_utils-") {
+		t.Errorf("插件cgroup为 %s, 期望在 %s 下", dir, parent)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "pids.max")); string(data) != "8" {
+		t.Errorf("pids.max 为 %q, 期望 8", data)
+	}
+
+	// 配置了 cgroup_parent 但无法使用时拒绝启动插件, 不改用 rlimit
+	limits := ResourceLimits{MemoryLimit: 64 << 20, CgroupParent: t.TempDir()}
+	if _, err := newSandbox("date_utils", limits); err == nil {
+		t.Error("cgroup_parent 不是cgroup目录时应返回错误")
+	}
+	if s, err := newSandbox("date_utils", ResourceLimits{MemoryLimit: 64 << 20}); err != nil || s.cgroupDir != "" {
+		t.Errorf("没有配置 cgroup_parent 时应使用 rlimit, 实际为 %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package shared
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// sandbox 非Linux平台不支持资源限制, 配置了限制的插件拒绝加载; 没有限制时只用于从校验过的副本启动插件
+type sandbox struct{}
+
+func newSandbox(name string, limits ResourceLimits) (*sandbox, error) {
+	if limits.IsZero() {
+		return &sandbox{}, nil
+	}
+	return nil, fmt.Errorf("插件 %s 配置了资源限制, 但 %s 平台不支持", name, runtime.GOOS)
+}
+
+// RunSandboxExec 非Linux平台不会经由宿主自身启动插件, 直接返回
+func RunSandboxExec() {}
+
+func (s *sandbox) prepare(cmd *exec.Cmd) error              { return nil }
+func (s *sandbox) started()                                 {}
+func (s *sandbox) exitReason(state *os.ProcessState) string { return "" }
+func (s *sandbox) cleanup()                                 {}
@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 设置了 rlimit 的插件经由测试程序自身启动
+func TestMain(m *testing.M) {
+	RunSandboxExec()
+	os.Exit(m.Run())
+}
+
+func TestResourceLimitsJSON(t *testing.T) {
+	var config SandboxConfig
+	data := `{"timeout":"5s","memory_limit":"1.5GiB","cpu_time":"1m30s","max_open_files":64,"cgroup_parent":"/sys/fs/cgroup/demo"}`
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatal(err)
+	}
+	want := ResourceLimits{MemoryLimit: 3 << 29, CPUTime: Duration(90 * time.Second), MaxOpenFiles: 64, CgroupParent: "/sys/fs/cgroup/demo"}
+	if config.Timeout != Duration(5*time.Second) || config.ResourceLimits != want {
+		t.Errorf("解析结果为 %+v, 期望 timeout 5s 和 %+v", config, want)
+	}
+
+	merged := want.Merge(ResourceLimits{MemoryLimit: 256 << 20, MaxProcesses: 8})
+	if merged.MemoryLimit != 256<<20 || merged.CPUTime != want.CPUTime || merged.MaxProcesses != 8 ||
+		merged.CgroupParent != want.CgroupParent {
+		t.Errorf("Merge = %+v", merged)
+	}
+	if !(ResourceLimits{CgroupParent: "/sys/fs/cgroup/demo"}).IsZero() {
+		t.Error("只设置了 cgroup_parent 时不算设置了限制")
+	}
+	if out, err := json.Marshal(merged.MemoryLimit); err != nil || string(out) != `"256MB"` {
+		t.Errorf("ByteSize 序列化为 %s, %v, 期望 \"256MB\"", out, err)
+	}
+
+	for _, invalid := range []string{
+		`{"memory_limit":"lots"}`,
+		`{"memory_limit":"10TB"}`,
+		`{"cpu_time":"-1s"}`,
+		`{"cpu_time":30}`,
+	} {
+		var limits ResourceLimits
+		if err := json.Unmarshal([]byte(invalid), &limits); err == nil {
+			t.Errorf("%s 应解析失败, 实际为 %+v", invalid, limits)
+		}
+	}
+}
+
+// chunkReader 每次只返回一个分片, 模拟错误信息跨两次读取
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func (r *chunkReader) Close() error { return nil }
+
+func TestLimitWatcher(t *testing.T) {
+	w := &limitWatcher{ReadCloser: &chunkReader{chunks: []string{
+		"runtime: program exceeds limit\nfatal error: out of me",
+		"mory\n",
+	}}}
+	if _, err := io.Copy(io.Discard, w); err != nil {
+		t.Fatal(err)
+	}
+
+	if reason := w.reason(ResourceLimits{}); reason != "" {
+		t.Errorf("没有设置内存限制时不应归因于内存, 实际为 %q", reason)
+	}
+	if reason := w.reason(ResourceLimits{MemoryLimit: 64 << 20}); !strings.Contains(reason, "内存超过限制 64MB") {
+		t.Errorf("reason = %q, 期望内存超过限制", reason)
+	}
+	if reason := w.reason(ResourceLimits{MaxOpenFiles: 16}); reason != "" {
+		t.Errorf("没有打开文件数的错误信息, 实际为 %q", reason)
+	}
+}
@@ -0,0 +1,335 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/rpc"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// superviseInterval 检查插件进程是否退出的间隔
+const superviseInterval = 200 * time.Millisecond
+
+// exitGracePeriod 调用连接断开后等待插件进程退出的时间
+const exitGracePeriod = 500 * time.Millisecond
+
+// errPluginDown 插件进程已退出或正在重启, 调用没有到达插件
+var errPluginDown = errors.New("插件不可用")
+
+// RestartPolicy 插件进程崩溃后的重启策略
+type RestartPolicy struct {
+	// InitialBackoff 第一次重启前的等待时间, 之后每次失败翻倍
+	InitialBackoff time.Duration
+	// MaxBackoff 重启等待时间的上限
+	MaxBackoff time.Duration
+	// MaxAttempts 连续重启失败的最大次数, 超过后不再重启; 0 表示不限制
+	MaxAttempts int
+	// RetryTimeout 幂等方法等待插件重启的最长时间
+	RetryTimeout time.Duration
+}
+
+// DefaultRestartPolicy 默认的重启策略
+var DefaultRestartPolicy = RestartPolicy{
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	MaxAttempts:    10,
+	RetryTimeout:   10 * time.Second,
+}
+
+// PluginState 插件的运行状态
+type PluginState string
+
+const (
+	PluginRunning    PluginState = "running"
+	PluginRestarting PluginState = "restarting"
+	PluginFailed     PluginState = "failed"
+)
+
+// PluginStatus 插件的运行状态和重启记录
+type PluginStatus struct {
+	Name        string
+	Path        string
+	State       PluginState
+	Restarts    int       // 成功重启的次数
+	LastError   string    // 最近一次崩溃或重启失败的原因
+	LastRestart time.Time // 最近一次成功重启的时间
+}
+
+// Healthy 插件是否正常运行
+func (s PluginStatus) Healthy() bool {
+	return s.State == PluginRunning
+}
+
+// SetRestartPolicy 设置插件崩溃后的重启策略, 对已加载的插件同样生效
+func (pm *PluginManager) SetRestartPolicy(policy RestartPolicy) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.policy = policy
+}
+
+func (pm *PluginManager) restartPolicy() RestartPolicy {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.policy
+}
+
+// Status 返回插件的运行状态
+func (pm *PluginManager) Status(pluginName string) (PluginStatus, bool) {
+	p, ok := pm.get(pluginName)
+	if !ok {
+		return PluginStatus{}, false
+	}
+	return p.status(), true
+}
+
+// Statuses 返回所有插件的运行状态, 按名称排序
+func (pm *PluginManager) Statuses() []PluginStatus {
+	pm.mu.RLock()
+	statuses := make([]PluginStatus, 0, len(pm.plugins))
+	for _, p := range pm.plugins {
+		statuses = append(statuses, p.status())
+	}
+	pm.mu.RUnlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// supervise 监控插件进程, 进程退出后按重启策略重新启动, 直到插件被卸载或重启失败次数超限
+func (pm *PluginManager) supervise(p *managedPlugin) {
+	ticker := time.NewTicker(superviseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !p.down() {
+			continue
+		}
+		if !pm.restart(p) {
+			return
+		}
+	}
+}
+
+// restart 以指数退避重新启动插件, 重新握手并检查ABI
+// 返回 false 表示插件已被卸载或放弃重启
+func (pm *PluginManager) restart(p *managedPlugin) bool {
+	policy := pm.restartPolicy()
+	backoff := policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		if policy.MaxAttempts > 0 && attempt > policy.MaxAttempts {
+			p.fail()
+			log.Printf("插件 %s 连续 %d 次重启失败, 不再重启", p.name, policy.MaxAttempts)
+			return false
+		}
+
+		select {
+		case <-p.stop:
+			return false
+		case <-time.After(backoff):
+		}
+
+		next, err := p.start()
+		if err == nil {
+			// 重启后的插件必须与首次加载时的ABI一致
+			if err = CompareABI(p.currentABI(), next.abi); err != nil {
+				next.client.Kill()
+			}
+		}
+		if err != nil {
+			p.setErr(err)
+			log.Printf("重启插件 %s 失败 (第 %d 次): %v", p.name, attempt, err)
+			backoff *= 2
+			if backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+			continue
+		}
+
+		if !p.replace(next) {
+			next.client.Kill()
+			return false
+		}
+		log.Printf("插件 %s 已重启 (累计 %d 次)", p.name, p.status().Restarts)
+		return true
+	}
+}
+
+// down 插件进程是否已退出或被标记为不可用
+func (p *managedPlugin) down() bool {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	if p.state != PluginRunning {
+		return true
+	}
+	if p.client.Exited() {
+		// 卸载时先关闭 stop 再结束进程, 不要把卸载当作崩溃
+		select {
+		case <-p.stop:
+			return false
+		default:
+		}
+		p.markDownLocked(p.exitCause())
+		return true
+	}
+	return false
+}
+
+// exitCause 返回插件进程退出的原因, 调用方需持有 stateMu
+func (p *managedPlugin) exitCause() error {
+	if p.runner != nil {
+		if reason := p.runner.ExitReason(); reason != "" {
+			return fmt.Errorf("进程因超出资源限制被结束: %s", reason)
+		}
+	}
+	return fmt.Errorf("进程已退出")
+}
+
+// lost 判断调用错误是否由插件进程退出或连接断开导致
+func (p *managedPlugin) lost(err error) bool {
+	p.stateMu.Lock()
+	exited := p.client.Exited()
+	p.stateMu.Unlock()
+	if exited {
+		return true
+	}
+	return errors.Is(err, rpc.ErrShutdown) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		status.Code(err) == codes.Unavailable
+}
+
+// lostCause 进程已退出时返回退出原因, 否则返回调用错误本身
+// 连接断开通常先于进程被回收, 稍等片刻以便报告进程被结束的原因
+func (p *managedPlugin) lostCause(err error) error {
+	deadline := time.Now().Add(exitGracePeriod)
+	for {
+		p.stateMu.Lock()
+		if p.client.Exited() {
+			cause := p.exitCause()
+			p.stateMu.Unlock()
+			return cause
+		}
+		p.stateMu.Unlock()
+
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func (p *managedPlugin) markDown(cause error) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	p.markDownLocked(cause)
+}
+
+// markDownLocked 将运行中的插件标记为正在重启, 调用方需持有 stateMu
+func (p *managedPlugin) markDownLocked(cause error) {
+	if p.state != PluginRunning {
+		return
+	}
+	log.Printf("插件 %s 不可用: %v", p.name, cause)
+	p.state = PluginRestarting
+	p.inst = nil
+	p.lastErr = cause
+	p.ready = make(chan struct{})
+}
+
+func (p *managedPlugin) setErr(err error) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	p.lastErr = err
+}
+
+// fail 放弃重启, 唤醒等待重启的调用
+// 只有正在重启的插件持有未关闭的 ready, 其他状态下不做处理
+func (p *managedPlugin) fail() {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	if p.state != PluginRestarting {
+		return
+	}
+	p.state = PluginFailed
+	close(p.ready)
+}
+
+// replace 用重启后的进程替换已退出的进程, 插件已被卸载或不在重启中时返回 false
+func (p *managedPlugin) replace(next *managedPlugin) bool {
+	// 等待仍在进行的调用结束, 它们连接的是已退出的进程, 会很快失败
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	if p.state != PluginRestarting {
+		return false
+	}
+	p.client.Kill()
+	p.client = next.client
+	p.runner = next.runner
+	p.abi = next.abi
+	p.inst = next.inst
+	p.state = PluginRunning
+	p.restarts++
+	p.lastRestart = time.Now()
+	close(p.ready)
+	return true
+}
+
+// waitReady 等待插件重启完成
+func (p *managedPlugin) waitReady(ctx context.Context, timeout time.Duration) error {
+	p.stateMu.Lock()
+	ready := p.ready
+	p.stateMu.Unlock()
+
+	select {
+	case <-ready:
+	case <-p.stop:
+		return NewError(CodeUnavailable, "插件 %s 已卸载", p.name)
+	case <-time.After(timeout):
+		return WrapError(CodeUnavailable, fmt.Errorf("%w: 等待插件 %s 重启超时", errPluginDown, p.name))
+	case <-ctx.Done():
+		return WrapError(CodeOf(ctx.Err()), fmt.Errorf("等待插件 %s 重启时调用结束: %w", p.name, ctx.Err()))
+	}
+
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	if p.state != PluginRunning {
+		return WrapError(CodeUnavailable, fmt.Errorf("%w: 插件 %s 重启失败: %v", errPluginDown, p.name, p.lastErr))
+	}
+	return nil
+}
+
+func (p *managedPlugin) status() PluginStatus {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	s := PluginStatus{
+		Name:        p.name,
+		Path:        p.path,
+		State:       p.state,
+		Restarts:    p.restarts,
+		LastRestart: p.lastRestart,
+	}
+	if p.lastErr != nil {
+		s.LastError = p.lastErr.Error()
+	}
+	return s
+}
@@ -0,0 +1,192 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testRestartPolicy 缩短重启等待, 让测试尽快观察到重启结果
+var testRestartPolicy = RestartPolicy{
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     200 * time.Millisecond,
+	MaxAttempts:    3,
+	RetryTimeout:   10 * time.Second,
+}
+
+// loadCrashPlugin 编译并加载 testdata/crashplugin, 返回插件管理器和可执行文件路径
+func loadCrashPlugin(t *testing.T) (*PluginManager, string) {
+	t.Helper()
+	path := buildPackage(t, filepath.Join(t.TempDir(), "crash"), "go-plugin-demo/src/shared/testdata/crashplugin")
+	pm := NewPluginManager()
+	pm.SetRestartPolicy(testRestartPolicy)
+	if err := pm.LoadPlugin(PluginConfig{Name: "crash", Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pm.UnloadAll)
+	return pm, path
+}
+
+// pluginPid 调用 crash.Pid 返回当前插件进程的 pid
+func pluginPid(t *testing.T, pm *PluginManager) int {
+	t.Helper()
+	result, err := pm.Invoke("crash", "Pid")
+	if err != nil {
+		t.Fatalf("调用 crash.Pid 失败: %v", err)
+	}
+	return toPid(t, result)
+}
+
+func toPid(t *testing.T, result interface{}) int {
+	t.Helper()
+	pid, err := strconv.Atoi(fmt.Sprint(result))
+	if err != nil {
+		t.Fatalf("pid %v 无效: %v", result, err)
+	}
+	return pid
+}
+
+// killProcess 强制结束插件进程, 模拟插件崩溃
+func killProcess(t *testing.T, pid int) {
+	t.Helper()
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Kill(); err != nil {
+		t.Fatalf("结束插件进程 %d 失败: %v", pid, err)
+	}
+}
+
+// waitState 等待插件进入指定状态, 返回此时的运行状态
+func waitState(t *testing.T, pm *PluginManager, state PluginState) PluginStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		status, _ := pm.Status("crash")
+		if status.State == state {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("插件状态为 %+v, 等待 %s 超时", status, state)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// 调用中插件进程被结束: 非幂等方法立即失败, 插件随后被重启一次
+func TestSupervisorRestartAfterKill(t *testing.T) {
+	pm, _ := loadCrashPlugin(t)
+	pid := pluginPid(t, pm)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pm.Invoke("crash", "Block")
+		done <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+	killProcess(t, pid)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errPluginDown) || CodeOf(err) != CodeUnavailable {
+			t.Errorf("非幂等方法应以 %s 立即失败, 实际为 %v", CodeUnavailable, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("插件进程退出后非幂等调用没有返回")
+	}
+
+	status := waitState(t, pm, PluginRunning)
+	if status.Restarts != 1 || status.LastError == "" {
+		t.Errorf("重启后的状态为 %+v, 期望重启 1 次并记录崩溃原因", status)
+	}
+	if next := pluginPid(t, pm); next == pid {
+		t.Errorf("重启后插件进程仍为 %d", pid)
+	}
+	if status, _ := pm.Status("crash"); status.Restarts != 1 {
+		t.Errorf("重启后调用不应再次重启, 重启次数为 %d", status.Restarts)
+	}
+}
+
+// 调用中插件进程被结束: 幂等方法等待插件重启后重试一次, 结果来自新进程
+func TestSupervisorRetryIdempotent(t *testing.T) {
+	pm, _ := loadCrashPlugin(t)
+	pid := pluginPid(t, pm)
+
+	type reply struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		result, err := pm.Invoke("crash", "Sleep", 1000)
+		done <- reply{result, err}
+	}()
+	time.Sleep(200 * time.Millisecond)
+	killProcess(t, pid)
+
+	var r reply
+	select {
+	case r = <-done:
+	case <-time.After(15 * time.Second):
+		t.Fatal("幂等调用没有在插件重启后返回")
+	}
+	if r.err != nil {
+		t.Fatalf("幂等方法应在插件重启后重试成功, 实际为 %v", r.err)
+	}
+	if next := toPid(t, r.result); next == pid {
+		t.Errorf("重试的结果来自已结束的进程 %d", pid)
+	}
+	if status, _ := pm.Status("crash"); status.State != PluginRunning || status.Restarts != 1 {
+		t.Errorf("重试后的状态为 %+v, 期望运行中且重启 1 次", status)
+	}
+}
+
+// 插件无法重新启动时, 连续失败 MaxAttempts 次后插件进入 failed 状态, 等待重启的调用随之失败
+func TestSupervisorFailedAfterMaxAttempts(t *testing.T) {
+	pm, path := loadCrashPlugin(t)
+	pid := pluginPid(t, pm)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pm.Invoke("crash", "Sleep", 1000)
+		done <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+	killProcess(t, pid)
+
+	status := waitState(t, pm, PluginFailed)
+	if status.Restarts != 0 || status.LastError == "" {
+		t.Errorf("重启失败后的状态为 %+v, 期望没有成功重启并记录失败原因", status)
+	}
+
+	select {
+	case err := <-done:
+		if CodeOf(err) != CodeUnavailable {
+			t.Errorf("插件重启失败后等待中的幂等调用应返回 %s, 实际为 %v", CodeUnavailable, err)
+		}
+	case <-time.After(testRestartPolicy.RetryTimeout):
+		t.Fatal("插件重启失败后等待中的调用没有被唤醒")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := pm.InvokeContext(ctx, "crash", "Pid"); CodeOf(err) != CodeUnavailable {
+		t.Errorf("failed 状态的插件应返回 %s, 实际为 %v", CodeUnavailable, err)
+	}
+
+	// 放弃重启后不会再次关闭 ready
+	p, _ := pm.get("crash")
+	p.fail()
+	if status, _ := pm.Status("crash"); status.State != PluginFailed {
+		t.Errorf("重复放弃重启后状态为 %s", status.State)
+	}
+}
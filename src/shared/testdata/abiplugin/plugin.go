@@ -0,0 +1,12 @@
+// abiplugin 用于测试 GenerateFromPackage 的插件包
+package abiplugin
+
+import "go-plugin-demo/src/shared"
+
+type Greeter struct{}
+
+func (g *Greeter) GetABI() (*shared.PluginABI, error) { return nil, nil }
+
+func (g *Greeter) Invoke(method string, args ...interface{}) (interface{}, error) { return nil, nil }
+
+func (g *Greeter) Greet(name string, times int) (string, error) { return name, nil }
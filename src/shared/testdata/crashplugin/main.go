@@ -0,0 +1,41 @@
+// crashplugin 用于测试插件进程崩溃后的重启和重试
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+)
+
+var funcs = map[string]dynamic_plugin_shared.DynamicFunc{
+	"Pid": dynamic_plugin_shared.Wrap(Pid, dynamic_plugin_shared.DynamicFunc{
+		Help:       "返回插件进程的 pid",
+		Idempotent: true,
+	}),
+	"Sleep": dynamic_plugin_shared.Wrap(Sleep, dynamic_plugin_shared.DynamicFunc{
+		Help:       "等待 ms 毫秒后返回插件进程的 pid",
+		Params:     []dynamic_plugin_shared.Param{{Name: "ms"}},
+		Idempotent: true,
+	}),
+	"Block": dynamic_plugin_shared.Wrap(Block, dynamic_plugin_shared.DynamicFunc{
+		Help: "阻塞到调用结束",
+	}),
+}
+
+func Pid() int { return os.Getpid() }
+
+func Sleep(ms int) int {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return os.Getpid()
+}
+
+func Block(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func main() {
+	dynamic_plugin_shared.Serve("crash", "1.0.0", funcs)
+}
@@ -0,0 +1,53 @@
+// serveplugin 用于测试 GenerateFromPackage 分析 Serve 函数表的插件包
+package main
+
+import (
+	"context"
+	"time"
+
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+)
+
+const version = "2.1.0"
+
+var textParam = []dynamic_plugin_shared.Param{{Name: "text", Description: "Text to echo"}}
+
+var funcs = map[string]dynamic_plugin_shared.DynamicFunc{
+	"Echo": dynamic_plugin_shared.Wrap(Echo, dynamic_plugin_shared.DynamicFunc{
+		Help:       "Echoes " + "text.",
+		Params:     textParam,
+		Idempotent: true,
+	}),
+	"Wait": dynamic_plugin_shared.Wrap(Wait, dynamic_plugin_shared.DynamicFunc{
+		Params: []dynamic_plugin_shared.Param{{"d", "", true, "1s", nil, "How long to wait"}},
+		Options: []dynamic_plugin_shared.Param{
+			{Name: "mode", Type: "string", Enum: []string{"fast", "slow"}},
+		},
+	}),
+	"Tags": dynamic_plugin_shared.Wrap(Tags, dynamic_plugin_shared.DynamicFunc{}),
+	"Raw": {
+		Help:    "Calls a hand written function.",
+		Params:  []dynamic_plugin_shared.Param{{Name: "x", Type: "int"}},
+		Returns: "float32",
+		HasArgs: true,
+		Call:    raw,
+	},
+}
+
+func Echo(text string) string { return text }
+
+func Wait(ctx context.Context, d time.Duration, opts dynamic_plugin_shared.Options) error {
+	return nil
+}
+
+func Tags(data []byte, at time.Time, extra map[string]any) (map[string]int, error) {
+	return nil, nil
+}
+
+func raw(args []interface{}, options dynamic_plugin_shared.Options) (interface{}, error) {
+	return nil, nil
+}
+
+func main() {
+	dynamic_plugin_shared.Serve("sample", version, funcs)
+}
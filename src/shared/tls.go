@@ -0,0 +1,69 @@
+package shared
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	dynamic_plugin_shared "go-plugin-demo/src/internal/plugin/shared"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// setTLS 按配置为插件连接启用双向TLS, 返回需要传给插件进程的环境变量
+func setTLS(config *goplugin.ClientConfig, tlsConfig TLSConfig) ([]string, error) {
+	if tlsConfig.AutoMTLS {
+		config.AutoMTLS = true
+		return nil, nil
+	}
+	if !tlsConfig.Enabled() {
+		return nil, nil
+	}
+
+	// 每次启动插件都重新读取证书, 证书轮换后重启的插件使用新证书
+	cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载宿主证书失败: %v", err)
+	}
+	pool, err := dynamic_plugin_shared.LoadCertPool(tlsConfig.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	serverName := tlsConfig.ServerName
+	if serverName == "" {
+		serverName = "localhost"
+	}
+	config.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return []string{
+		dynamic_plugin_shared.EnvTLSCAFile + "=" + tlsConfig.CAFile,
+		dynamic_plugin_shared.EnvTLSCertFile + "=" + tlsConfig.PluginCertFile,
+		dynamic_plugin_shared.EnvTLSKeyFile + "=" + tlsConfig.PluginKeyFile,
+	}, nil
+}
+
+// connectClient 启动插件进程并建立RPC连接
+// 启用TLS时确认插件端同样启用了TLS, 插件不支持时连接失败, 不会退回明文
+func connectClient(client *goplugin.Client, config *goplugin.ClientConfig, tlsConfig TLSConfig) (goplugin.ClientProtocol, error) {
+	if tlsConfig.AutoMTLS {
+		if _, err := client.Start(); err != nil {
+			return nil, err
+		}
+		// 支持 AutoMTLS 的插件会在握手信息中返回自己的证书
+		if config.TLSConfig == nil || config.TLSConfig.RootCAs == nil {
+			return nil, fmt.Errorf("插件未在握手中返回证书, 不支持 AutoMTLS")
+		}
+	}
+
+	rpcClient, err := client.Client()
+	if err == nil && tlsConfig.Enabled() {
+		err = rpcClient.Ping()
+	}
+	if err != nil && tlsConfig.Enabled() {
+		return nil, fmt.Errorf("TLS握手失败, 插件可能未启用TLS: %v", err)
+	}
+	return rpcClient, err
+}
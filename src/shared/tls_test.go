@@ -0,0 +1,154 @@
+package shared
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA 测试用的CA, 证书和私钥以PEM格式写入 dir
+type testCA struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir, name string) *testCA {
+	t.Helper()
+	ca := &testCA{t: t, dir: dir}
+	ca.cert, ca.key = ca.issue(name, &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	ca.file = filepath.Join(dir, name+".pem")
+	writePEM(t, ca.file, "CERTIFICATE", ca.cert.Raw)
+	return ca
+}
+
+// issue 签发证书, CA 自身的证书为自签名
+func (ca *testCA) issue(name string, template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey) {
+	ca.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parent, signer := template, key
+	if ca.cert != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	return cert, key
+}
+
+// issueFiles 签发证书并写入 dir, 返回证书和私钥的路径
+func (ca *testCA) issueFiles(name string, usage x509.ExtKeyUsage) (string, string) {
+	ca.t.Helper()
+	cert, key := ca.issue(name, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	})
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(ca.dir, name+".pem"), filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(ca.t, certFile, "CERTIFICATE", cert.Raw)
+	writePEM(ca.t, keyFile, "EC PRIVATE KEY", der)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 自定义证书: 证书由同一CA签发时插件正常加载; CA不符或缺少证书时加载失败, 不会退回明文连接
+func TestLoadPluginTLS(t *testing.T) {
+	path := buildPlugin(t, t.TempDir(), "date_utils")
+	dir := t.TempDir()
+	ca := newTestCA(t, dir, "ca")
+	hostCert, hostKey := ca.issueFiles("host", x509.ExtKeyUsageClientAuth)
+	pluginCert, pluginKey := ca.issueFiles("plugin", x509.ExtKeyUsageServerAuth)
+	other := newTestCA(t, t.TempDir(), "other")
+	otherHostCert, otherHostKey := other.issueFiles("host", x509.ExtKeyUsageClientAuth)
+
+	valid := TLSConfig{
+		CAFile:         ca.file,
+		CertFile:       hostCert,
+		KeyFile:        hostKey,
+		PluginCertFile: pluginCert,
+		PluginKeyFile:  pluginKey,
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *TLSConfig)
+		wantErr string
+	}{
+		{name: "同一CA签发的证书", modify: func(c *TLSConfig) {}},
+		{name: "宿主信任的CA不同", modify: func(c *TLSConfig) { c.CAFile = other.file }, wantErr: "TLS握手失败"},
+		{name: "宿主证书由其他CA签发", modify: func(c *TLSConfig) {
+			c.CertFile, c.KeyFile = otherHostCert, otherHostKey
+		}, wantErr: "TLS握手失败"},
+		{name: "插件证书不存在", modify: func(c *TLSConfig) {
+			c.PluginCertFile = filepath.Join(dir, "missing.pem")
+		}, wantErr: "TLS握手失败"},
+		{name: "宿主证书不存在", modify: func(c *TLSConfig) {
+			c.CertFile = filepath.Join(dir, "missing.pem")
+		}, wantErr: "加载宿主证书失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig := valid
+			tt.modify(&tlsConfig)
+			pm := NewPluginManager()
+			defer pm.UnloadAll()
+			err := pm.LoadConfig(&Config{
+				Plugins:  []PluginConfig{{Name: "date_utils", Path: path}},
+				Security: SecurityConfig{TLS: tlsConfig},
+			})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("加载插件失败: %v", err)
+				}
+				start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				if _, err := pm.Invoke("date_utils", "Between", start, start.AddDate(0, 0, 1)); err != nil {
+					t.Errorf("通过TLS调用 date_utils.Between 失败: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("TLS配置错误时加载插件应返回包含 %q 的错误, 实际为 %v", tt.wantErr, err)
+			}
+			if len(pm.Names()) != 0 {
+				t.Errorf("TLS配置错误时不应注册插件, 已注册 %v", pm.Names())
+			}
+		})
+	}
+}
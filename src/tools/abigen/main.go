@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"go-plugin-demo/src/shared"
+)
+
+// abigen 在构建时通过静态分析插件源码生成 abi.json, 无需编译和运行插件
+// 使用 dynamic_plugin_shared.Serve 的插件按函数表生成, 插件名和版本以源码为准;
+// 否则插件需要实现 DynamicPlugin 接口
+func main() {
+	name := flag.String("name", "", "插件名称, 使用 Serve 的插件必须与源码一致")
+	version := flag.String("version", "1.0.0", "插件版本, 只用于没有使用 Serve 的插件")
+	pkg := flag.String("pkg", "", "插件包路径, 例如 ./src/plugins/calculator")
+	output := flag.String("o", "", "输出的ABI描述文件路径")
+	flag.Parse()
+
+	if *name == "" || *pkg == "" || *output == "" {
+		flag.Usage()
+		log.Fatal("必须指定 -name、-pkg 和 -o")
+	}
+
+	abi, err := shared.NewABIGenerator().GenerateFromPackage(*name, *version, *pkg)
+	if err != nil {
+		log.Fatalf("生成插件 %s ABI失败: %v", *name, err)
+	}
+
+	if err := shared.WriteABIManifest(*output, abi); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("已生成 %s", *output)
+}